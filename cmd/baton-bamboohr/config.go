@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
 	"github.com/conductorone/baton-sdk/pkg/field"
 )
 
@@ -15,9 +16,134 @@ var (
 		field.WithDescription("The api key for your BambooHR account"),
 		field.WithRequired(true),
 	)
+	CustomTablesConfigField = field.StringField(
+		"custom-tables-config",
+		field.WithDescription("Path to a YAML file mapping BambooHR custom tables to resource types"),
+	)
+	GroupByFieldsField = field.StringSliceField(
+		"group-by-fields",
+		field.WithDescription("BambooHR list fields, by alias or name, to sync as groups (e.g. \"Cost Center\")"),
+	)
+	EmployeeConcurrencyField = field.IntField(
+		"employee-concurrency",
+		field.WithDescription("How many per-employee requests, such as employee photos and reads of per_employee custom tables, to send to BambooHR at once"),
+		field.WithDefaultValue(client.DefaultConcurrency),
+	)
+	DepartmentDivisionsField = field.StringSliceField(
+		"department-divisions",
+		field.WithDescription("Assign departments to divisions as <department>=<division> when both are group-by fields. Other departments are assigned from employee data"),
+	)
+	PhotoSizeField = field.StringField(
+		"photo-size",
+		field.WithDescription("Size of employee photos used as user icons: original, large, medium, small, xs or tiny"),
+		field.WithDefaultValue(client.PhotoSizeSmall),
+	)
+	AccountTypesField = field.StringSliceField(
+		"account-types",
+		field.WithDescription("Map employment statuses to account types as <status>=<human|service|system>, e.g. \"Service Account=service\". Other employees are human"),
+	)
+	UserFilterField = field.StringField(
+		"user-filter",
+		field.WithDescription("Only sync employees matching this expression over report fields, e.g. 'status == \"Active\" && location != \"Test\"'"),
+	)
+	IncludeTerminatedField = field.BoolField(
+		"include-terminated",
+		field.WithDescription("Sync inactive employees, with the status set by --terminated-status"),
+		field.WithDefaultValue(true),
+	)
+	TerminatedRetentionDaysField = field.IntField(
+		"terminated-retention-days",
+		field.WithDescription("Days after their termination date that terminated employees keep being synced. 0 keeps them indefinitely"),
+		field.WithDefaultValue(0),
+	)
+	TerminatedStatusField = field.StringField(
+		"terminated-status",
+		field.WithDescription("Status of synced inactive employees: deleted for those with a termination date and disabled for the rest, disabled, or enabled"),
+		field.WithDefaultValue("deleted"),
+	)
+	SkipFutureHiresField = field.BoolField(
+		"skip-future-hires",
+		field.WithDescription("Do not sync employees whose hire date is in the future"),
+	)
+	DataSourceField = field.StringField(
+		"data-source",
+		field.WithDescription("BambooHR API to read employees from: custom-report or datasets"),
+		field.WithDefaultValue(client.DataSourceCustomReport),
+	)
+	RecordHTTPField = field.StringField(
+		"record-http",
+		field.WithDescription("Write a capture of BambooHR requests and responses to this file, with credentials removed and personal data pseudonymized"),
+	)
+	ReplayHTTPField = field.StringField(
+		"replay-http",
+		field.WithDescription("Answer BambooHR requests from a capture written with --record-http instead of calling BambooHR"),
+	)
+	LogBodiesField = field.BoolField(
+		"log-bodies",
+		field.WithDescription("Log BambooHR request and response bodies at debug level, with sensitive fields redacted"),
+	)
+	ProfileFieldsField = field.StringSliceField(
+		"profile-fields",
+		field.WithDescription("Extra BambooHR employee fields, by alias or id, to copy into user profiles, each optionally followed by transforms, e.g. \"workEmail:domain\" or \"employeeNumber:hash\". Sensitive fields are refused"),
+	)
+	ProfileHashSaltField = field.StringField(
+		"profile-hash-salt",
+		field.WithDescription("Secret salt for the hash profile field transform. Keep it stable so hashes join up across syncs"),
+	)
+	SeniorManagerIndirectReportsField = field.IntField(
+		"senior-manager-indirect-reports",
+		field.WithDescription("Sync a senior_manager role for active employees with more than this many active indirect reports. 0 disables the role"),
+		field.WithDefaultValue(0),
+	)
+	PendingJobChangesField = field.BoolField(
+		"pending-job-changes",
+		field.WithDescription("Add each employee's next future-dated jobInfo and employmentStatus changes to their profile"),
+	)
+	EmploymentHistoryField = field.BoolField(
+		"employment-history",
+		field.WithDescription("Add each employee's jobInfo and employmentStatus history, hire dates and rehire status to their profile"),
+	)
+	JobChangeEventsField = field.BoolField(
+		"job-change-events",
+		field.WithDescription("Report hires, terminations, rehires, and department and division changes of group-by fields, as grant and revoke events when they take effect"),
+	)
+	OrgChartReportField = field.StringField(
+		"org-chart-report",
+		field.WithDescription("Write dangling supervisors, terminated supervisors and reporting cycles found on each sync to this JSON file"),
+	)
+	UnsafeAllowSensitiveFieldsField = field.BoolField(
+		"unsafe-allow-sensitive-fields",
+		field.WithDescription("UNSAFE: allow syncing sensitive fields such as SSNs, compensation, bank details and dates of birth. Logs a warning on every sync"),
+	)
 	configurationFields = []field.SchemaField{
 		CompanyDomainField,
 		ApiKeyField,
+		CustomTablesConfigField,
+		EmployeeConcurrencyField,
+		GroupByFieldsField,
+		DepartmentDivisionsField,
+		PhotoSizeField,
+		AccountTypesField,
+		UserFilterField,
+		IncludeTerminatedField,
+		TerminatedRetentionDaysField,
+		TerminatedStatusField,
+		SkipFutureHiresField,
+		DataSourceField,
+		RecordHTTPField,
+		ReplayHTTPField,
+		LogBodiesField,
+		ProfileFieldsField,
+		ProfileHashSaltField,
+		OrgChartReportField,
+		PendingJobChangesField,
+		EmploymentHistoryField,
+		JobChangeEventsField,
+		SeniorManagerIndirectReportsField,
+		UnsafeAllowSensitiveFieldsField,
 	}
-	Configuration = field.NewConfiguration(configurationFields)
+	Configuration = field.NewConfiguration(
+		configurationFields,
+		field.FieldsMutuallyExclusive(RecordHTTPField, ReplayHTTPField),
+	)
 )
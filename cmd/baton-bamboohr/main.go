@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/conductorone/baton-bamboohr/pkg/connector"
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
 	"github.com/conductorone/baton-sdk/pkg/config"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/field"
@@ -47,10 +48,100 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 		return nil, err
 	}
 
+	opts := []connector.Option{
+		connector.WithDataSource(v.GetString(DataSourceField.FieldName)),
+		connector.WithPhotoSize(v.GetString(PhotoSizeField.FieldName)),
+		connector.WithTerminatedEmployees(
+			v.GetBool(IncludeTerminatedField.FieldName),
+			v.GetInt(TerminatedRetentionDaysField.FieldName),
+		),
+		connector.WithTerminatedStatus(v.GetString(TerminatedStatusField.FieldName)),
+	}
+
+	clientOpts := make([]client.Option, 0)
+	if v.GetBool(LogBodiesField.FieldName) {
+		clientOpts = append(clientOpts, client.WithBodyLogging())
+	}
+	if path := v.GetString(RecordHTTPField.FieldName); path != "" {
+		l.Warn("recording BambooHR requests", zap.String("path", path))
+		clientOpts = append(clientOpts, client.WithRecording(path))
+	}
+	if path := v.GetString(ReplayHTTPField.FieldName); path != "" {
+		l.Warn("replaying BambooHR requests, BambooHR will not be contacted", zap.String("path", path))
+		clientOpts = append(clientOpts, client.WithReplay(path))
+	}
+	if len(clientOpts) > 0 {
+		bambooHRClient, err := client.New(
+			ctx,
+			v.GetString(ApiKeyField.FieldName),
+			v.GetString(CompanyDomainField.FieldName),
+			clientOpts...,
+		)
+		if err != nil {
+			l.Error("error creating BambooHR client", zap.Error(err))
+			return nil, err
+		}
+		// The client must be replaced before options that configure it.
+		opts = append([]connector.Option{connector.WithClient(bambooHRClient)}, opts...)
+	}
+
+	if path := v.GetString(CustomTablesConfigField.FieldName); path != "" {
+		mappings, err := connector.LoadCustomTablesConfig(path)
+		if err != nil {
+			l.Error("error loading custom tables config", zap.Error(err))
+			return nil, err
+		}
+		opts = append(opts, connector.WithCustomTables(mappings))
+	}
+	if concurrency := v.GetInt(EmployeeConcurrencyField.FieldName); concurrency != client.DefaultConcurrency {
+		opts = append(opts, connector.WithEmployeeConcurrency(concurrency))
+	}
+
+	if entries := v.GetStringSlice(AccountTypesField.FieldName); len(entries) > 0 {
+		opts = append(opts, connector.WithAccountTypes(entries))
+	}
+	if v.GetBool(SkipFutureHiresField.FieldName) {
+		opts = append(opts, connector.WithoutFutureHires())
+	}
+	if expression := v.GetString(UserFilterField.FieldName); expression != "" {
+		opts = append(opts, connector.WithUserFilter(expression))
+	}
+	if fields := v.GetStringSlice(GroupByFieldsField.FieldName); len(fields) > 0 {
+		opts = append(opts, connector.WithGroupByFields(fields))
+	}
+	if entries := v.GetStringSlice(DepartmentDivisionsField.FieldName); len(entries) > 0 {
+		opts = append(opts, connector.WithDepartmentDivisions(entries))
+	}
+	if fields := v.GetStringSlice(ProfileFieldsField.FieldName); len(fields) > 0 {
+		opts = append(opts, connector.WithProfileFields(fields))
+	}
+	if salt := v.GetString(ProfileHashSaltField.FieldName); salt != "" {
+		opts = append(opts, connector.WithProfileHashSalt(salt))
+	}
+	if v.GetBool(PendingJobChangesField.FieldName) {
+		opts = append(opts, connector.WithPendingJobChanges())
+	}
+	if v.GetBool(EmploymentHistoryField.FieldName) {
+		opts = append(opts, connector.WithEmploymentHistory())
+	}
+	if v.GetBool(JobChangeEventsField.FieldName) {
+		opts = append(opts, connector.WithJobChangeEvents())
+	}
+	if path := v.GetString(OrgChartReportField.FieldName); path != "" {
+		opts = append(opts, connector.WithOrgChartReport(path))
+	}
+	if reports := v.GetInt(SeniorManagerIndirectReportsField.FieldName); reports > 0 {
+		opts = append(opts, connector.WithSeniorManagerThreshold(reports))
+	}
+	if v.GetBool(UnsafeAllowSensitiveFieldsField.FieldName) {
+		opts = append(opts, connector.WithSensitiveFieldsAllowed())
+	}
+
 	cb, err := connector.New(
 		ctx,
 		v.GetString(CompanyDomainField.FieldName),
 		v.GetString(ApiKeyField.FieldName),
+		opts...,
 	)
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
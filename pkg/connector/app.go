@@ -0,0 +1,182 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const (
+	appLoginAccessEntitlement = "login_access"
+	companyLogoAssetId        = "company_logo"
+)
+
+// AppResourceType represents the BambooHR tenant itself. Users and groups are
+// annotated as its children, and employees with a BambooHR login are granted
+// login_access.
+type AppResourceType struct {
+	resourceType       *v2.ResourceType
+	bambooHRClient     client.Client
+	directory          *directory
+	customerDomain     string
+	childResourceTypes []string
+}
+
+func (o *AppResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func (o *AppResourceType) List(
+	ctx context.Context,
+	parentResourceID *v2.ResourceId,
+	_ *pagination.Token,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID != nil {
+		return nil, "", nil, nil
+	}
+
+	info, ratelimitData, err := o.bambooHRClient.GetCompanyInformation(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	newResource, err := o.appResource(info)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return []*v2.Resource{newResource}, "", outputAnnotations, nil
+}
+
+func (o *AppResourceType) Entitlements(
+	_ context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Entitlement,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(
+			resource,
+			appLoginAccessEntitlement,
+			entitlement.WithGrantableTo(resourceTypeUser),
+			entitlement.WithDisplayName(resource.DisplayName+" Login Access"),
+			entitlement.WithDescription("Can log in to "+resource.DisplayName),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns login_access for every enabled login account that belongs
+// to a synced employee. Accounts without an employee have no user resource,
+// and an API key that cannot list login accounts gets no grants.
+func (o *AppResourceType) Grants(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Grant,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	loginUsers, ratelimitData, err := o.directory.loginUsers(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	if err != nil {
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+
+	rv := make([]*v2.Grant, 0)
+	for _, loginUser := range loginUsers {
+		if !loginUser.IsEnabled() || !employees.includes(loginUser.EmployeeId.String()) {
+			continue
+		}
+		employeeId := loginUser.EmployeeId.String()
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     employeeId,
+		}
+		rv = append(rv, grant.NewGrant(resource, appLoginAccessEntitlement, principal))
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func appBuilder(
+	directory *directory,
+	customerDomain string,
+	childResourceTypes []string,
+) *AppResourceType {
+	return &AppResourceType{
+		resourceType:       resourceTypeApp,
+		bambooHRClient:     directory.bambooHRClient,
+		directory:          directory,
+		customerDomain:     customerDomain,
+		childResourceTypes: childResourceTypes,
+	}
+}
+
+// appResource converts the company information into the app Resource.
+func (o *AppResourceType) appResource(info *client.CompanyInformation) (*v2.Resource, error) {
+	displayName := info.DisplayName
+	if displayName == "" {
+		displayName = info.LegalName
+	}
+	if displayName == "" {
+		displayName = o.customerDomain
+	}
+	profile := map[string]interface{}{
+		"company_domain": o.customerDomain,
+		"display_name":   info.DisplayName,
+		"legal_name":     info.LegalName,
+	}
+
+	resourceOptions := []resource.ResourceOption{
+		resource.WithAnnotation(&v2.ExternalLink{Url: tenantURL(o.customerDomain)}),
+	}
+	for _, childResourceType := range o.childResourceTypes {
+		resourceOptions = append(
+			resourceOptions,
+			resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: childResourceType}),
+		)
+	}
+
+	return resource.NewAppResource(
+		displayName,
+		resourceTypeApp,
+		o.customerDomain,
+		[]resource.AppTraitOption{
+			resource.WithAppProfile(profile),
+			resource.WithAppHelpURL(tenantURL(o.customerDomain)),
+			resource.WithAppLogo(&v2.AssetRef{Id: companyLogoAssetId}),
+		},
+		resourceOptions...,
+	)
+}
+
+// listedAsAppChild reports whether a syncer is being asked for the children of
+// the app resource. Users and groups are already listed at the top level, so
+// their syncers return nothing instead of fetching everything twice.
+func listedAsAppChild(parentResourceID *v2.ResourceId) bool {
+	return parentResourceID != nil && parentResourceID.ResourceType == resourceTypeApp.Id
+}
+
+// tenantURL returns the web address of a BambooHR tenant.
+func tenantURL(customerDomain string) string {
+	return fmt.Sprintf("https://%s.bamboohr.com", customerDomain)
+}
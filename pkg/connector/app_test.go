@@ -0,0 +1,97 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	connector, err := New(ctx, "mock-company", "mock-access-token")
+	require.Nil(t, err)
+	setBaseUrl(t, connector, server.URL)
+
+	c := appBuilder(connector.directory, "mock-company", []string{resourceTypeUser.Id, resourceTypeEmployment.Id})
+	resources, _, listAnnotations, err := c.List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	test.AssertNoRatelimitAnnotations(t, listAnnotations)
+	require.Len(t, resources, 1)
+	require.Equal(t, "Mock Company", resources[0].DisplayName)
+
+	t.Run("should link to the tenant and its children", func(t *testing.T) {
+		annos := annotations.Annotations(resources[0].Annotations)
+		externalLink := &v2.ExternalLink{}
+		ok, err := annos.Pick(externalLink)
+		require.Nil(t, err)
+		require.True(t, ok)
+		require.Equal(t, "https://mock-company.bamboohr.com", externalLink.Url)
+
+		childResourceTypes := make([]string, 0)
+		for _, annotation := range annos {
+			childResourceType := &v2.ChildResourceType{}
+			if annotation.MessageIs(childResourceType) {
+				require.Nil(t, annotation.UnmarshalTo(childResourceType))
+				childResourceTypes = append(childResourceTypes, childResourceType.ResourceTypeId)
+			}
+		}
+		require.Equal(t, []string{resourceTypeUser.Id, resourceTypeEmployment.Id}, childResourceTypes)
+	})
+
+	t.Run("should not list users and groups again as its children", func(t *testing.T) {
+		parent := resources[0].Id
+		users, _, _, err := userBuilder(connector.directory).List(ctx, parent, &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, users)
+
+		groups, _, _, err := employmentBuilder(connector.directory).List(ctx, parent, &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, groups)
+	})
+
+	t.Run("should grant login access to enabled employee accounts", func(t *testing.T) {
+		grants, _, _, err := c.Grants(ctx, resources[0], &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, grants, 1)
+		require.Equal(t, "id", grants[0].Principal.Id.Resource)
+	})
+
+	t.Run("should grant nothing when login accounts cannot be listed", func(t *testing.T) {
+		mock := newClientMock()
+		mock.ListLoginUsersFunc = func(_ context.Context) ([]*client.LoginUser, *v2.RateLimitDescription, error) {
+			return nil, nil, fmt.Errorf("bambooHR-client: error getting login users %w", client.ErrPermissionDenied)
+		}
+		directory := newDirectory(mock, nil, userConfig{}, nil)
+
+		grants, _, _, err := appBuilder(directory, "mock-company", nil).Grants(ctx, resources[0], &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, grants)
+
+		users, _, _, err := userBuilder(directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, users, 1)
+		require.Len(t, mock.ListLoginUsersCalls(), 1)
+	})
+
+	t.Run("should serve the company logo", func(t *testing.T) {
+		contentType, body, err := connector.Asset(ctx, &v2.AssetRef{Id: companyLogoAssetId})
+		require.Nil(t, err)
+		defer body.Close()
+		require.Equal(t, "image/png", contentType)
+		data, err := io.ReadAll(body)
+		require.Nil(t, err)
+		require.NotEmpty(t, data)
+	})
+}
@@ -0,0 +1,227 @@
+package connector
+
+import (
+	"context"
+	"errors"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	assetsTable                = "assets"
+	assetAssignedToEntitlement = "assigned_to"
+	assetCategoryField         = "assetCategory"
+	assetDescriptionField      = "assetDescription"
+	assetSerialNumberField     = "assetSerialNumber"
+	assetDateAssignedField     = "assetDateAssigned"
+	assetDateReturnedField     = "assetDateReturned"
+)
+
+type AssetResourceType struct {
+	resourceType   *v2.ResourceType
+	bambooHRClient client.Client
+	directory      *directory
+}
+
+func (o *AssetResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// List returns one resource per serial number in the Assets table. When an
+// asset appears on several rows, the row that has not been returned wins.
+func (o *AssetResourceType) List(
+	ctx context.Context,
+	parentResourceID *v2.ResourceId,
+	_ *pagination.Token,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if listedAsAppChild(parentResourceID) {
+		return nil, "", nil, nil
+	}
+
+	rows, ratelimitData, err := o.rows(ctx)
+	if err != nil {
+		if errors.Is(err, client.ErrPermissionDenied) {
+			ctxzap.Extract(ctx).Warn(
+				"api key cannot read the BambooHR assets table, skipping assets",
+				zap.Error(err),
+			)
+			return nil, "", WithRateLimitAnnotations(ratelimitData), nil
+		}
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+	// employees.all still holds the employees the terminated employee
+	// options leave out.
+	terminated := make(map[string]bool)
+	for _, user := range employees.all {
+		if !user.IsActive() {
+			terminated[user.Id] = true
+		}
+	}
+
+	rv := make([]*v2.Resource, 0)
+	for _, row := range currentAssetRows(rows) {
+		newResource, err := assetResource(row, terminated)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, newResource)
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func (o *AssetResourceType) Entitlements(
+	_ context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Entitlement,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			assetAssignedToEntitlement,
+			entitlement.WithGrantableTo(resourceTypeUser),
+			entitlement.WithDisplayName(resource.DisplayName+" Assigned To"),
+			entitlement.WithDescription("Has been issued "+resource.DisplayName),
+		),
+	}, "", nil, nil
+}
+
+func (o *AssetResourceType) Grants(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Grant,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	rows, ratelimitData, err := o.rows(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		if errors.Is(err, client.ErrPermissionDenied) {
+			ctxzap.Extract(ctx).Warn(
+				"api key cannot read the BambooHR assets table, skipping asset grants",
+				zap.Error(err),
+			)
+			return nil, "", outputAnnotations, nil
+		}
+		return nil, "", outputAnnotations, err
+	}
+
+	row, ok := currentAssetRows(rows)[resource.Id.Resource]
+	if !ok || isAssetReturned(row) {
+		return nil, "", outputAnnotations, nil
+	}
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	if err != nil {
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+	if !employees.includes(row.EmployeeId()) {
+		return nil, "", outputAnnotations, nil
+	}
+
+	principal := &v2.ResourceId{
+		ResourceType: resourceTypeUser.Id,
+		Resource:     row.EmployeeId(),
+	}
+	return []*v2.Grant{
+		grant.NewGrant(
+			resource,
+			assetAssignedToEntitlement,
+			principal,
+			grant.WithGrantMetadata(map[string]interface{}{
+				"dateAssigned": row.Get(assetDateAssignedField),
+			}),
+		),
+	}, "", outputAnnotations, nil
+}
+
+func assetBuilder(directory *directory) *AssetResourceType {
+	return &AssetResourceType{
+		resourceType:   resourceTypeAsset,
+		bambooHRClient: directory.bambooHRClient,
+		directory:      directory,
+	}
+}
+
+// rows reads the Assets table once per sync, rather than once per asset.
+func (o *AssetResourceType) rows(ctx context.Context) ([]client.TableRow, *v2.RateLimitDescription, error) {
+	return readOnce(ctx, o.directory, "table:"+assetsTable, func(ctx context.Context) ([]client.TableRow, *v2.RateLimitDescription, error) {
+		return o.bambooHRClient.ListTableRows(ctx, assetsTable)
+	})
+}
+
+func isAssetReturned(row client.TableRow) bool {
+	_, returned := client.ParseDate(row.Get(assetDateReturnedField))
+	return returned
+}
+
+// currentAssetRows keys the Assets table by serial number, preferring rows that
+// are still assigned. Rows without a serial number cannot be keyed and are
+// skipped.
+func currentAssetRows(rows []client.TableRow) map[string]client.TableRow {
+	current := make(map[string]client.TableRow)
+	for _, row := range rows {
+		serial := row.Get(assetSerialNumberField)
+		if serial == "" {
+			continue
+		}
+		existing, ok := current[serial]
+		if !ok || isAssetReturned(existing) {
+			current[serial] = row
+		}
+	}
+	return current
+}
+
+// assetResource converts a row of the Assets table into a Resource.
+func assetResource(row client.TableRow, terminated map[string]bool) (*v2.Resource, error) {
+	serial := row.Get(assetSerialNumberField)
+	assigned := !isAssetReturned(row)
+	profile := map[string]interface{}{
+		"serial_number":                   serial,
+		"category":                        row.Get(assetCategoryField),
+		"description":                     row.Get(assetDescriptionField),
+		"date_assigned":                   row.Get(assetDateAssignedField),
+		"date_returned":                   row.Get(assetDateReturnedField),
+		"assigned":                        assigned,
+		"assigned_to_terminated_employee": assigned && terminated[row.EmployeeId()],
+	}
+	if assigned {
+		profile["assigned_employee_id"] = row.EmployeeId()
+	}
+
+	displayName := serial
+	if description := row.Get(assetDescriptionField); description != "" {
+		displayName = description + " (" + serial + ")"
+	}
+
+	return resource.NewGroupResource(
+		displayName,
+		resourceTypeAsset,
+		serial,
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(profile),
+		},
+	)
+}
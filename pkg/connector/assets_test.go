@@ -0,0 +1,112 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssets(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	bambooHRClient, err := client.New(
+		ctx,
+		"mock-access-token",
+		"mock-company",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bambooHRClient.SetBaseUrl(server.URL)
+	c := assetBuilder(newDirectory(bambooHRClient, nil, userConfig{}, nil))
+
+	resources, _, listAnnotations, err := c.List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	test.AssertNoRatelimitAnnotations(t, listAnnotations)
+	require.Len(t, resources, 2)
+
+	byId := make(map[string]*v2.Resource)
+	for _, r := range resources {
+		byId[r.Id.Resource] = r
+	}
+	require.Contains(t, byId, "C02XYZ")
+	require.Contains(t, byId, "B-100")
+
+	t.Run("should grant assets that are still assigned", func(t *testing.T) {
+		grants, _, _, err := c.Grants(ctx, byId["C02XYZ"], &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, grants, 1)
+		require.Equal(t, "id", grants[0].Principal.Id.Resource)
+	})
+
+	t.Run("should not grant returned assets", func(t *testing.T) {
+		grants, _, _, err := c.Grants(ctx, byId["B-100"], &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, grants)
+	})
+
+	t.Run("should read the assets table once per sync", func(t *testing.T) {
+		mock := &test.ClientMock{
+			ListUsersPageFunc: func(_ context.Context, _ *client.ReportFilters, _ string, _ ...string) ([]*client.User, string, *v2.RateLimitDescription, error) {
+				return []*client.User{{Id: "1", Status: client.UserStatusActive}}, "", nil, nil
+			},
+			ListTableRowsFunc: func(_ context.Context, _ string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+				return []client.TableRow{
+					{"employeeId": "1", assetSerialNumberField: "A-1"},
+					{"employeeId": "1", assetSerialNumberField: "A-2"},
+				}, nil, nil
+			},
+		}
+		c := assetBuilder(newDirectory(mock, nil, userConfig{}, nil))
+		resources, _, _, err := c.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, resources, 2)
+		for _, asset := range resources {
+			grants, _, _, err := c.Grants(ctx, asset, &pagination.Token{})
+			require.Nil(t, err)
+			require.Len(t, grants, 1)
+		}
+		require.Len(t, mock.ListTableRowsCalls(), 1)
+	})
+
+	t.Run("should flag assets of terminated employees that are not synced", func(t *testing.T) {
+		server := test.NewFakeServer()
+		defer server.Close()
+		alan := server.AddEmployee(map[string]string{"firstName": "Alan", "lastName": "Turing", "status": "Inactive"})
+		server.AddTableRow(assetsTable, alan, client.TableRow{assetSerialNumberField: "A-1", assetDateAssignedField: "2024-01-01"})
+
+		for _, dataSource := range []string{client.DataSourceCustomReport, client.DataSourceDatasets} {
+			c := assetBuilder(newDirectory(fakeServerClient(t, server, dataSource), nil, userConfig{excludeTerminated: true}, nil))
+			resources, _, _, err := c.List(ctx, nil, &pagination.Token{})
+			require.Nil(t, err)
+			require.Len(t, resources, 1)
+			groupTrait, err := resource.GetGroupTrait(resources[0])
+			require.Nil(t, err)
+			require.Equal(t, true, groupTrait.Profile.AsMap()["assigned_to_terminated_employee"], dataSource)
+		}
+	})
+
+	t.Run("should skip assets the api key cannot read", func(t *testing.T) {
+		server := test.NewFakeServer()
+		defer server.Close()
+		server.InjectFailure(test.Failure{Path: "/tables/assets", Status: http.StatusForbidden})
+		c := assetBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{}, nil))
+
+		resources, _, _, err := c.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, resources)
+		grants, _, _, err := c.Grants(ctx, byId["C02XYZ"], &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, grants)
+	})
+}
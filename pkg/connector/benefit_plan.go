@@ -0,0 +1,181 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	benefitPlanEnrolledEntitlement = "enrolled"
+	benefitEnrollmentWaived        = "waived"
+)
+
+type BenefitPlanResourceType struct {
+	resourceType   *v2.ResourceType
+	bambooHRClient client.Client
+	directory      *directory
+}
+
+func (o *BenefitPlanResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func (o *BenefitPlanResourceType) List(
+	ctx context.Context,
+	parentResourceID *v2.ResourceId,
+	_ *pagination.Token,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if listedAsAppChild(parentResourceID) {
+		return nil, "", nil, nil
+	}
+
+	benefits, ratelimitData, err := o.bambooHRClient.ListCompanyBenefits(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		if errors.Is(err, client.ErrPermissionDenied) {
+			ctxzap.Extract(ctx).Warn(
+				"api key cannot list BambooHR benefit plans, skipping benefit plans",
+				zap.Error(err),
+			)
+			return nil, "", outputAnnotations, nil
+		}
+		return nil, "", outputAnnotations, err
+	}
+
+	rv := make([]*v2.Resource, 0)
+	for _, benefit := range benefits {
+		newResource, err := benefitPlanResource(benefit)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, newResource)
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func (o *BenefitPlanResourceType) Entitlements(
+	_ context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Entitlement,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			benefitPlanEnrolledEntitlement,
+			entitlement.WithGrantableTo(resourceTypeUser),
+			entitlement.WithDisplayName(resource.DisplayName+" Enrolled"),
+			entitlement.WithDescription("Enrolled in the "+resource.DisplayName+" benefit plan"),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns one grant per synced employee whose coverage in the plan has
+// not ended. Waived enrollments are not grants. Enrollments are read once per
+// sync, rather than once per plan.
+func (o *BenefitPlanResourceType) Grants(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Grant,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	enrollments, ratelimitData, err := readOnce(ctx, o.directory, "employeeBenefits", func(ctx context.Context) ([]*client.EmployeeBenefit, *v2.RateLimitDescription, error) {
+		return o.bambooHRClient.ListEmployeeBenefits(ctx)
+	})
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		if errors.Is(err, client.ErrPermissionDenied) {
+			ctxzap.Extract(ctx).Warn(
+				"api key cannot list BambooHR benefit enrollments, skipping benefit plan grants",
+				zap.Error(err),
+			)
+			return nil, "", outputAnnotations, nil
+		}
+		return nil, "", outputAnnotations, err
+	}
+
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	if err != nil {
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+
+	now := time.Now()
+	rv := make([]*v2.Grant, 0)
+	for _, enrollment := range enrollments {
+		if enrollment.CompanyBenefitId != resource.Id.Resource || !employees.includes(enrollment.EmployeeId) {
+			continue
+		}
+		if strings.EqualFold(enrollment.EnrollmentStatus, benefitEnrollmentWaived) {
+			continue
+		}
+		if end, ok := client.ParseDate(enrollment.CoverageEndDate); ok && end.Before(now) {
+			continue
+		}
+
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     enrollment.EmployeeId,
+		}
+		rv = append(rv, grant.NewGrant(
+			resource,
+			benefitPlanEnrolledEntitlement,
+			principal,
+			grant.WithGrantMetadata(map[string]interface{}{
+				"coverageStartDate": enrollment.CoverageStartDate,
+				"coverageEndDate":   enrollment.CoverageEndDate,
+			}),
+		))
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func benefitPlanBuilder(directory *directory) *BenefitPlanResourceType {
+	return &BenefitPlanResourceType{
+		resourceType:   resourceTypeBenefitPlan,
+		bambooHRClient: directory.bambooHRClient,
+		directory:      directory,
+	}
+}
+
+// benefitPlanResource converts a BambooHR company benefit into a Resource.
+// Only descriptive fields are copied into the profile, never amounts.
+func benefitPlanResource(benefit *client.CompanyBenefit) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"benefit_id":         benefit.Id,
+		"benefit_name":       benefit.BenefitName,
+		"benefit_type_id":    benefit.CompanyBenefitTypeId,
+		"benefit_start_date": benefit.StartDate,
+		"benefit_end_date":   benefit.EndDate,
+	}
+
+	return resource.NewGroupResource(
+		benefit.BenefitName,
+		resourceTypeBenefitPlan,
+		benefit.Id,
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(profile),
+		},
+	)
+}
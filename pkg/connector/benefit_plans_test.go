@@ -0,0 +1,95 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenefitPlans(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	bambooHRClient, err := client.New(
+		ctx,
+		"mock-access-token",
+		"mock-company",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bambooHRClient.SetBaseUrl(server.URL)
+	c := benefitPlanBuilder(newDirectory(bambooHRClient, nil, userConfig{}, nil))
+
+	resources, nextToken, listAnnotations, err := c.List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	test.AssertNoRatelimitAnnotations(t, listAnnotations)
+	require.Empty(t, nextToken)
+	require.Len(t, resources, 1)
+	require.Equal(t, "Medical", resources[0].DisplayName)
+	benefitPlan := resources[0]
+
+	t.Run("should list the enrolled entitlement", func(t *testing.T) {
+		entitlements, _, _, err := c.Entitlements(ctx, resources[0], &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, entitlements, 1)
+		require.Equal(t, benefitPlanEnrolledEntitlement, entitlements[0].Slug)
+	})
+
+	t.Run("should only grant current enrollments", func(t *testing.T) {
+		grants, _, grantAnnotations, err := c.Grants(ctx, resources[0], &pagination.Token{})
+		require.Nil(t, err)
+		test.AssertNoRatelimitAnnotations(t, grantAnnotations)
+		require.Len(t, grants, 1)
+		require.Equal(t, "id", grants[0].Principal.Id.Resource)
+	})
+
+	t.Run("should read enrollments once per sync", func(t *testing.T) {
+		mock := &test.ClientMock{
+			ListUsersPageFunc: func(_ context.Context, _ *client.ReportFilters, _ string, _ ...string) ([]*client.User, string, *v2.RateLimitDescription, error) {
+				return []*client.User{{Id: "1", Status: client.UserStatusActive}}, "", nil, nil
+			},
+			ListCompanyBenefitsFunc: func(_ context.Context) ([]*client.CompanyBenefit, *v2.RateLimitDescription, error) {
+				return []*client.CompanyBenefit{{Id: "1", BenefitName: "Medical"}, {Id: "2", BenefitName: "Dental"}}, nil, nil
+			},
+			ListEmployeeBenefitsFunc: func(_ context.Context) ([]*client.EmployeeBenefit, *v2.RateLimitDescription, error) {
+				return []*client.EmployeeBenefit{
+					{EmployeeId: "1", CompanyBenefitId: "1"},
+					{EmployeeId: "1", CompanyBenefitId: "2"},
+				}, nil, nil
+			},
+		}
+		c := benefitPlanBuilder(newDirectory(mock, nil, userConfig{}, nil))
+		plans, _, _, err := c.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, plans, 2)
+		for _, plan := range plans {
+			grants, _, _, err := c.Grants(ctx, plan, &pagination.Token{})
+			require.Nil(t, err)
+			require.Len(t, grants, 1)
+		}
+		require.Len(t, mock.ListEmployeeBenefitsCalls(), 1)
+	})
+
+	t.Run("should skip benefit plans the api key cannot read", func(t *testing.T) {
+		server := test.NewFakeServer()
+		defer server.Close()
+		server.InjectFailure(test.Failure{Path: "/benefit/", Status: http.StatusForbidden})
+		c := benefitPlanBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{}, nil))
+
+		resources, _, _, err := c.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, resources)
+		grants, _, _, err := c.Grants(ctx, benefitPlan, &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, grants)
+	})
+}
@@ -0,0 +1,133 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	capturePath := filepath.Join(t.TempDir(), "capture.json")
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	seedFakeServer(server)
+	server.APIKey = "secret-api-key"
+	server.AddTableRow("customSystemAccess", "1", client.TableRow{
+		"customSystem":     "okta",
+		"customSystemName": "Okta",
+		"comment":          "Requested by Ada's manager",
+	})
+	systemAccess := WithCustomTables([]*CustomTableMapping{
+		{Table: "customSystemAccess", ResourceTypeId: "system", KeyColumn: "customSystem", DisplayColumn: "customSystemName"},
+	})
+
+	recording, err := client.New(ctx, "secret-api-key", "customer-domain", client.WithRecording(capturePath))
+	require.Nil(t, err)
+	recording.SetBaseUrl(server.URL)
+	recorded := syncSnapshot(ctx, t, recording, systemAccess)
+
+	t.Run("should sanitize the capture", func(t *testing.T) {
+		capture, err := os.ReadFile(capturePath)
+		require.Nil(t, err)
+		for _, secret := range []string{"secret-api-key", "customer-domain", "Lovelace", "ada@example.com", "Hopper", "Acme Inc.", "Ada's manager"} {
+			require.NotContains(t, string(capture), secret)
+		}
+	})
+
+	t.Run("should write one interaction per line", func(t *testing.T) {
+		capture, err := os.ReadFile(capturePath)
+		require.Nil(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(capture), "\n"), "\n")
+		require.JSONEq(t, `{"version": 2}`, lines[0])
+		require.Greater(t, len(lines), 1)
+		for _, line := range lines[1:] {
+			interaction := &client.Interaction{}
+			require.Nil(t, json.Unmarshal([]byte(line), interaction))
+			require.NotEmpty(t, interaction.Path)
+		}
+	})
+
+	t.Run("should replay the capture", func(t *testing.T) {
+		server.Close()
+		// A sync that exits mid-write leaves a partial last line behind.
+		file, err := os.OpenFile(capturePath, os.O_APPEND|os.O_WRONLY, 0)
+		require.Nil(t, err)
+		_, err = file.WriteString(`{"method":"GET","pa`)
+		require.Nil(t, err)
+		require.Nil(t, file.Close())
+
+		replaying, err := client.New(ctx, "secret-api-key", "customer-domain", client.WithReplay(capturePath))
+		require.Nil(t, err)
+		replayed := syncSnapshot(ctx, t, replaying, systemAccess)
+
+		for _, key := range []string{"resource_types", "resources", "entitlements", "grants"} {
+			require.Equal(t, snapshotIds(recorded[key]), snapshotIds(replayed[key]), key)
+		}
+		for _, resource := range replayed["resources"] {
+			require.NotEqual(t, "Ada Lovelace", resource["displayName"])
+		}
+	})
+}
+
+func TestRecordResponsesThatAreNotJSON(t *testing.T) {
+	ctx := context.Background()
+	capturePath := filepath.Join(t.TempDir(), "capture.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/xml")
+		_, _ = writer.Write([]byte(`<users><user id="1"><firstName>Ada</firstName><lastName>Lovelace</lastName></user></users>`))
+	}))
+	defer server.Close()
+
+	recording, err := client.New(ctx, "secret-api-key", "customer-domain", client.WithRecording(capturePath))
+	require.Nil(t, err)
+	recording.SetBaseUrl(server.URL)
+	_, _, err = recording.ListLoginUsers(ctx)
+	require.Error(t, err)
+
+	capture, err := os.ReadFile(capturePath)
+	require.Nil(t, err)
+	require.NotContains(t, string(capture), "Lovelace")
+
+	lines := strings.Split(strings.TrimSuffix(string(capture), "\n"), "\n")
+	require.Len(t, lines, 2)
+	interaction := &client.Interaction{}
+	require.Nil(t, json.Unmarshal([]byte(lines[1]), interaction))
+	require.Equal(t, "application/xml", interaction.ContentType)
+	require.True(t, strings.HasPrefix(string(interaction.ResponseData), client.Redacted))
+}
+
+func syncSnapshot(ctx context.Context, t *testing.T, bambooHRClient client.Client, opts ...Option) map[string][]map[string]interface{} {
+	bambooHR, err := New(ctx, "customer-domain", "secret-api-key", append([]Option{WithClient(bambooHRClient)}, opts...)...)
+	require.Nil(t, err)
+	connector, err := connectorbuilder.NewConnector(ctx, bambooHR)
+	require.Nil(t, err)
+
+	snapshot := make(map[string][]map[string]interface{})
+	require.Nil(t, json.Unmarshal(test.SyncSnapshot(ctx, t, connector), &snapshot))
+	return snapshot
+}
+
+func snapshotIds(objects []map[string]interface{}) []string {
+	ids := make([]string, 0, len(objects))
+	for _, object := range objects {
+		id, err := json.Marshal(object["id"])
+		if err != nil {
+			panic(err)
+		}
+		ids = append(ids, string(id))
+	}
+	return ids
+}
@@ -2,48 +2,187 @@ package client
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 )
 
 const (
-	UsersListUrlPath = "reports/custom"
+	UsersListUrlPath        = "reports/custom"
+	CompanyBenefitsUrlPath  = "benefit/company_benefit"
+	EmployeeBenefitsUrlPath = "benefit/employee_benefit"
+	ChangedTablesUrlPath    = "employees/changed/tables"
+	MetaFieldsUrlPath       = "meta/fields"
+	MetaListsUrlPath        = "meta/lists"
+	MetaUsersUrlPath        = "meta/users"
+	CompanyInfoUrlPath      = "company_information"
+	CompanyLogoUrlPath      = "company_information/logo"
+	EmployeesUrlPath        = "employees"
 )
 
+// Photo sizes accepted by the employee photo endpoint.
+const (
+	PhotoSizeOriginal = "original"
+	PhotoSizeLarge    = "large"
+	PhotoSizeMedium   = "medium"
+	PhotoSizeSmall    = "small"
+	PhotoSizeXS       = "xs"
+	PhotoSizeTiny     = "tiny"
+)
+
+var PhotoSizes = []string{
+	PhotoSizeOriginal,
+	PhotoSizeLarge,
+	PhotoSizeMedium,
+	PhotoSizeSmall,
+	PhotoSizeXS,
+	PhotoSizeTiny,
+}
+
+// changedSinceEpoch asks the changed-tables endpoint for every row rather than
+// only recently changed ones.
+const changedSinceEpoch = "1970-01-01T00:00:00Z"
+
+// defaultUserFields are the report fields every employee listing requests.
+var defaultUserFields = []string{
+	"firstName",
+	"lastName",
+	"supervisor",
+	"supervisorEId",
+	"supervisorId",
+	"supervisorEmail",
+	"workEmail",
+	"status",
+	"photoUploaded",
+	"hireDate",
+	"terminationDate",
+	"employmentHistoryStatus",
+}
+
 type BambooHRClient struct {
 	wrapper       *uhttp.BaseHttpClient
 	ApiKey        string
 	CompanyDomain string
 	BaseUrl       *url.URL
+	employees     EmployeeSource
+	// allowSensitiveFields disables the DeniedFields and DeniedTables checks.
+	allowSensitiveFields bool
+
+	// fieldsMu guards fields, the /meta/fields response the denylist resolves
+	// field ids and names against. It is fetched once per client.
+	fieldsMu sync.Mutex
+	fields   []*Field
 }
 
+// Client is the set of BambooHR endpoints the connector uses. BambooHRClient
+// implements it over HTTP; tests can substitute test.ClientMock, which is
+// generated from it.
 type Client interface {
-	ListUsers(ctx context.Context, pagination string) ([]*User, *v2.RateLimitDescription, error)
+	ListUsers(ctx context.Context, extraFields ...string) ([]*User, *v2.RateLimitDescription, error)
+	ListFilteredUsers(ctx context.Context, filters *ReportFilters, extraFields ...string) ([]*User, *v2.RateLimitDescription, error)
+	ListUsersPage(ctx context.Context, filters *ReportFilters, page string, extraFields ...string) ([]*User, string, *v2.RateLimitDescription, error)
+	ListCompanyBenefits(ctx context.Context) ([]*CompanyBenefit, *v2.RateLimitDescription, error)
+	ListEmployeeBenefits(ctx context.Context) ([]*EmployeeBenefit, *v2.RateLimitDescription, error)
+	ListTableRows(ctx context.Context, table string) ([]TableRow, *v2.RateLimitDescription, error)
+	ListEmployeeTableRows(ctx context.Context, employeeId string, table string) ([]TableRow, *v2.RateLimitDescription, error)
+	ListListFields(ctx context.Context) ([]*ListField, *v2.RateLimitDescription, error)
+	GetCompanyInformation(ctx context.Context) (*CompanyInformation, *v2.RateLimitDescription, error)
+	GetCompanyLogo(ctx context.Context) ([]byte, string, *v2.RateLimitDescription, error)
+	GetEmployeePhoto(ctx context.Context, employeeId string, size string) ([]byte, string, *v2.RateLimitDescription, error)
+	ListLoginUsers(ctx context.Context) ([]*LoginUser, *v2.RateLimitDescription, error)
+	Verify(ctx context.Context) error
+}
+
+var _ Client = (*BambooHRClient)(nil)
+
+// Option configures the HTTP client used to reach BambooHR.
+type Option func(httpClient *http.Client) error
+
+// WithRecording writes a sanitized capture of every request and response to
+// path. See RecordingTransport.
+func WithRecording(path string) Option {
+	return func(httpClient *http.Client) error {
+		transport, err := NewRecordingTransport(httpClient.Transport, path)
+		if err != nil {
+			return err
+		}
+		httpClient.Transport = transport
+		return nil
+	}
+}
+
+// WithReplay answers requests from a capture at path instead of calling
+// BambooHR. See ReplayTransport.
+func WithReplay(path string) Option {
+	return func(httpClient *http.Client) error {
+		transport, err := NewReplayTransport(path)
+		if err != nil {
+			return err
+		}
+		httpClient.Transport = transport
+		return nil
+	}
 }
 
-func New(ctx context.Context, apiKey string, companyDomain string) (*BambooHRClient, error) {
+// WithBodyLogging logs every request and response body at debug level. Bodies
+// are redacted with RedactBody first.
+func WithBodyLogging() Option {
+	return func(httpClient *http.Client) error {
+		httpClient.Transport = &bodyLoggingTransport{next: httpClient.Transport}
+		return nil
+	}
+}
+
+func New(ctx context.Context, apiKey string, companyDomain string, opts ...Option) (*BambooHRClient, error) {
 	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, nil))
 	if err != nil {
 		return nil, err
 	}
+	for _, opt := range opts {
+		err := opt(httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
 	wrapper := uhttp.NewBaseHttpClient(httpClient)
 
 	baseUrl := url.URL{
 		Scheme: "https",
 		Host:   APIDomain,
 	}
-	return &BambooHRClient{
+	c := &BambooHRClient{
 		wrapper:       wrapper,
 		ApiKey:        apiKey,
 		CompanyDomain: companyDomain,
 		BaseUrl:       &baseUrl,
-	}, nil
+	}
+	c.employees = &customReportSource{client: c}
+	return c, nil
+}
+
+// SetDataSource selects the backend employees are listed from, either
+// DataSourceCustomReport or DataSourceDatasets.
+func (c *BambooHRClient) SetDataSource(dataSource string) error {
+	switch dataSource {
+	case DataSourceCustomReport:
+		c.employees = &customReportSource{client: c}
+	case DataSourceDatasets:
+		c.employees = &datasetSource{client: c, pageSize: DatasetPageSize}
+	default:
+		return fmt.Errorf("bambooHR-client: unknown data source %q", dataSource)
+	}
+	return nil
+}
+
+// AllowSensitiveFields lets requests read DeniedFields and DeniedTables.
+func (c *BambooHRClient) AllowSensitiveFields() {
+	c.allowSensitiveFields = true
 }
 
 // SetBaseUrl shim for local integration tests.
@@ -55,46 +194,404 @@ func (c *BambooHRClient) SetBaseUrl(rawUrl string) {
 	c.BaseUrl = baseUrl
 }
 
-func (c *BambooHRClient) ListUsers(ctx context.Context) (
+// ListUsers runs the employees report. Any extraFields are requested in
+// addition to the default ones and are returned in User.Fields.
+func (c *BambooHRClient) ListUsers(ctx context.Context, extraFields ...string) (
+	[]*User,
+	*v2.RateLimitDescription,
+	error,
+) {
+	return c.ListFilteredUsers(ctx, nil, extraFields...)
+}
+
+// ListFilteredUsers is ListUsers with filters applied by BambooHR. It reads
+// every page of employees.
+func (c *BambooHRClient) ListFilteredUsers(
+	ctx context.Context,
+	filters *ReportFilters,
+	extraFields ...string,
+) (
 	[]*User,
 	*v2.RateLimitDescription,
 	error,
 ) {
-	users := &ReportUserResults{}
+	rv := make([]*User, 0)
+	page := ""
+	for {
+		users, nextPage, ratelimitData, err := c.ListUsersPage(ctx, filters, page, extraFields...)
+		if err != nil {
+			return nil, ratelimitData, err
+		}
+		rv = append(rv, users...)
+		if nextPage == "" {
+			return rv, ratelimitData, nil
+		}
+		page = nextPage
+	}
+}
+
+// ListUsersPage is ListFilteredUsers for one page of employees. The first
+// page is "", and the returned token is the next page's, or "" after the
+// last. Custom reports return every employee on the first page.
+func (c *BambooHRClient) ListUsersPage(
+	ctx context.Context,
+	filters *ReportFilters,
+	page string,
+	extraFields ...string,
+) (
+	[]*User,
+	string,
+	*v2.RateLimitDescription,
+	error,
+) {
+	fields := slices.Clone(defaultUserFields)
+	for _, extraField := range extraFields {
+		if slices.Contains(fields, extraField) {
+			continue
+		}
+		denied, ratelimitData, err := c.isDeniedField(ctx, extraField)
+		if err != nil {
+			return nil, "", ratelimitData, fmt.Errorf("bambooHR-client: error listing users %w", err)
+		}
+		if denied {
+			return nil, "", nil, fmt.Errorf("bambooHR-client: error listing users: %w: %q", ErrSensitiveField, extraField)
+		}
+		fields = append(fields, extraField)
+	}
+
+	users, nextPage, ratelimitData, err := c.employees.ListEmployees(ctx, filters, fields, page)
+	if err != nil {
+		return nil, "", ratelimitData, fmt.Errorf("bambooHR-client: error listing users %w", err)
+	}
+	return users, nextPage, ratelimitData, nil
+}
+
+// isDeniedField reports whether requesting field would read one of
+// DeniedFields. Fields can be requested by alias, by numeric id or, for the
+// Datasets API, under a different name, so a field that is not denied as
+// given is looked up in /meta/fields and its alias, name and type are checked
+// too.
+func (c *BambooHRClient) isDeniedField(ctx context.Context, field string) (bool, *v2.RateLimitDescription, error) {
+	if c.allowSensitiveFields {
+		return false, nil, nil
+	}
+	if IsDeniedField(field) || IsDeniedField(datasetFieldName(field)) {
+		return true, nil, nil
+	}
+
+	fields, ratelimitData, err := c.ListFields(ctx)
+	if err != nil {
+		return false, ratelimitData, err
+	}
+	for _, candidate := range fields {
+		if string(candidate.Id) != field &&
+			!strings.EqualFold(candidate.Alias, field) &&
+			!strings.EqualFold(candidate.Name, field) {
+			continue
+		}
+		if IsDeniedField(candidate.Alias) ||
+			IsDeniedField(candidate.Name) ||
+			IsDeniedFieldType(candidate.Type) {
+			return true, ratelimitData, nil
+		}
+	}
+	return false, ratelimitData, nil
+}
+
+// checkModelFields rejects a response model that decodes one of
+// DeniedFields. It guards endpoints that return a fixed set of fields, such
+// as benefits, against a model growing a sensitive field later.
+func (c *BambooHRClient) checkModelFields(model interface{}) error {
+	if c.allowSensitiveFields {
+		return nil
+	}
+	for _, field := range jsonFields(model) {
+		if IsDeniedField(field) {
+			return fmt.Errorf("%w: %q", ErrSensitiveField, field)
+		}
+	}
+	return nil
+}
+
+// ListFields returns every employee field from /meta/fields. The response is
+// fetched once and reused for the lifetime of the client.
+func (c *BambooHRClient) ListFields(ctx context.Context) (
+	[]*Field,
+	*v2.RateLimitDescription,
+	error,
+) {
+	c.fieldsMu.Lock()
+	defer c.fieldsMu.Unlock()
+	if c.fields != nil {
+		return c.fields, nil, nil
+	}
+
+	fields := make([]*Field, 0)
+	reqURL := c.newUnPaginatedURL(MetaFieldsUrlPath, url.Values{})
+
+	ratelimitData, err := c.makeRequest(
+		ctx,
+		reqURL,
+		&fields,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing fields %w", err)
+	}
+	c.fields = fields
+	return fields, ratelimitData, nil
+}
+
+// ListCompanyBenefits returns every benefit plan configured for the company.
+func (c *BambooHRClient) ListCompanyBenefits(ctx context.Context) (
+	[]*CompanyBenefit,
+	*v2.RateLimitDescription,
+	error,
+) {
+	err := c.checkModelFields(CompanyBenefit{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("bambooHR-client: error listing company benefits: %w", err)
+	}
+	benefits := make([]*CompanyBenefit, 0)
+	reqURL := c.newUnPaginatedURL(CompanyBenefitsUrlPath, url.Values{})
+
+	ratelimitData, err := c.makeRequest(
+		ctx,
+		reqURL,
+		&benefits,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing company benefits %w", err)
+	}
+	return benefits, ratelimitData, nil
+}
+
+// ListEmployeeBenefits returns the benefit enrollments of every employee.
+func (c *BambooHRClient) ListEmployeeBenefits(ctx context.Context) (
+	[]*EmployeeBenefit,
+	*v2.RateLimitDescription,
+	error,
+) {
+	err := c.checkModelFields(EmployeeBenefit{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("bambooHR-client: error listing employee benefits: %w", err)
+	}
+	benefits := make([]*EmployeeBenefit, 0)
+	reqURL := c.newUnPaginatedURL(EmployeeBenefitsUrlPath, url.Values{})
+
+	ratelimitData, err := c.makeRequest(
+		ctx,
+		reqURL,
+		&benefits,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing employee benefits %w", err)
+	}
+	return benefits, ratelimitData, nil
+}
+
+// ListTableRows returns every row of the given employee table for all
+// employees, using the changed-tables endpoint so that a single request covers
+// the whole company.
+func (c *BambooHRClient) ListTableRows(ctx context.Context, table string) (
+	[]TableRow,
+	*v2.RateLimitDescription,
+	error,
+) {
+	if !c.allowSensitiveFields && IsDeniedTable(table) {
+		return nil, nil, fmt.Errorf("bambooHR-client: error listing %s table rows: %w", table, ErrSensitiveField)
+	}
+
+	changed := &ChangedTableResults{}
 	v := url.Values{}
-	v.Set("format", "json")
-	reqURL := c.newUnPaginatedURL(UsersListUrlPath, v)
+	v.Set("since", changedSinceEpoch)
+	reqURL := c.newUnPaginatedURL(ChangedTablesUrlPath+"/"+url.PathEscape(table), v)
+
+	ratelimitData, err := c.makeRequest(
+		ctx,
+		reqURL,
+		changed,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing %s table rows %w", table, err)
+	}
+	return changed.Rows(), ratelimitData, nil
+}
+
+// ListEmployeeTableRows returns one employee's rows of the given table. It
+// reaches tables that the changed-tables endpoint does not cover, at the cost
+// of a request per employee; see ForEachEmployee.
+func (c *BambooHRClient) ListEmployeeTableRows(ctx context.Context, employeeId string, table string) (
+	[]TableRow,
+	*v2.RateLimitDescription,
+	error,
+) {
+	if !c.allowSensitiveFields && IsDeniedTable(table) {
+		return nil, nil, fmt.Errorf("bambooHR-client: error listing %s table rows: %w", table, ErrSensitiveField)
+	}
+
+	rows := make([]TableRow, 0)
+	reqURL := c.newUnPaginatedURL(
+		strings.Join(
+			[]string{EmployeesUrlPath, url.PathEscape(employeeId), "tables", url.PathEscape(table)},
+			"/",
+		),
+		url.Values{},
+	)
+
+	ratelimitData, err := c.makeRequest(
+		ctx,
+		reqURL,
+		&rows,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing %s table rows for employee %s %w", table, employeeId, err)
+	}
+
+	rv := make([]TableRow, 0, len(rows))
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		if row.EmployeeId() == "" {
+			row["employeeId"] = employeeId
+		}
+		rv = append(rv, row)
+	}
+	return rv, ratelimitData, nil
+}
+
+// ListListFields returns every list-type field along with its options,
+// including archived ones.
+func (c *BambooHRClient) ListListFields(ctx context.Context) (
+	[]*ListField,
+	*v2.RateLimitDescription,
+	error,
+) {
+	lists := make([]*ListField, 0)
+	reqURL := c.newUnPaginatedURL(MetaListsUrlPath, url.Values{})
+
+	ratelimitData, err := c.makeRequest(
+		ctx,
+		reqURL,
+		&lists,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing list fields %w", err)
+	}
+	return lists, ratelimitData, nil
+}
+
+// GetCompanyInformation returns the company's name and contact details.
+func (c *BambooHRClient) GetCompanyInformation(ctx context.Context) (
+	*CompanyInformation,
+	*v2.RateLimitDescription,
+	error,
+) {
+	info := &CompanyInformation{}
+	reqURL := c.newUnPaginatedURL(CompanyInfoUrlPath, url.Values{})
+
+	ratelimitData, err := c.makeRequest(
+		ctx,
+		reqURL,
+		info,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error getting company information %w", err)
+	}
+	return info, ratelimitData, nil
+}
 
-	listUsersReqBody := ReqFields{
-		Title: "ConductorOne Employees List Report",
-		Fields: []string{
-			"firstName",
-			"lastName",
-			"supervisor",
-			"supervisorEId",
-			"supervisorId",
-			"supervisorEmail",
-			"workEmail",
-			"status",
-		},
+// GetCompanyLogo returns the company logo image and its content type.
+func (c *BambooHRClient) GetCompanyLogo(ctx context.Context) (
+	[]byte,
+	string,
+	*v2.RateLimitDescription,
+	error,
+) {
+	reqURL := c.newUnPaginatedURL(CompanyLogoUrlPath, url.Values{})
+
+	logo, contentType, ratelimitData, err := c.makeRawRequest(ctx, reqURL)
+	if err != nil {
+		return nil, "", ratelimitData, fmt.Errorf("bambooHR-client: error getting company logo %w", err)
 	}
-	bodyBytes, err := json.Marshal(listUsersReqBody)
+	return logo, contentType, ratelimitData, nil
+}
+
+// GetEmployeePhoto returns an employee's photo at the given size and its
+// content type. An error matching ErrNotFound means the employee has no
+// photo.
+func (c *BambooHRClient) GetEmployeePhoto(ctx context.Context, employeeId string, size string) (
+	[]byte,
+	string,
+	*v2.RateLimitDescription,
+	error,
+) {
+	reqURL := c.newUnPaginatedURL(
+		strings.Join(
+			[]string{EmployeesUrlPath, url.PathEscape(employeeId), "photo", url.PathEscape(size)},
+			"/",
+		),
+		url.Values{},
+	)
+
+	photo, contentType, ratelimitData, err := c.makeRawRequest(ctx, reqURL)
 	if err != nil {
-		return nil, nil, err
+		return nil, "", ratelimitData, fmt.Errorf("bambooHR-client: error getting employee photo %w", err)
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(photo)
 	}
-	body := strings.NewReader(string(bodyBytes))
+	return photo, contentType, ratelimitData, nil
+}
+
+// ListLoginUsers returns the BambooHR login accounts, which are distinct from
+// employees. Accounts that belong to an employee carry its EmployeeId.
+func (c *BambooHRClient) ListLoginUsers(ctx context.Context) (
+	[]*LoginUser,
+	*v2.RateLimitDescription,
+	error,
+) {
+	loginUsers := make(map[string]*LoginUser)
+	reqURL := c.newUnPaginatedURL(MetaUsersUrlPath, url.Values{})
 
 	ratelimitData, err := c.makeRequest(
 		ctx,
 		reqURL,
-		users,
-		http.MethodPost,
-		body,
+		&loginUsers,
+		http.MethodGet,
+		nil,
 	)
 	if err != nil {
-		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing users %w", err)
+		return nil, ratelimitData, fmt.Errorf("bambooHR-client: error listing login users %w", err)
+	}
+
+	ids := make([]string, 0, len(loginUsers))
+	for id := range loginUsers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	rv := make([]*LoginUser, 0, len(ids))
+	for _, id := range ids {
+		if loginUsers[id] != nil {
+			rv = append(rv, loginUsers[id])
+		}
 	}
-	return users.Users, ratelimitData, nil
+	return rv, ratelimitData, nil
 }
 
 // Verify - Makes an API call to verify that the given credentials work.
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+const (
+	DatasetsEmployeeUrlPath = "datasets/employee"
+	DatasetPageSize         = 500
+)
+
+// datasetFieldNames maps report aliases to Datasets API field names where the
+// two differ. Other fields are requested under their report alias.
+var datasetFieldNames = map[string]string{
+	"id":                      "employeeId",
+	"workEmail":               "email",
+	"supervisorEId":           "supervisorEid",
+	"employmentHistoryStatus": "employmentStatus",
+}
+
+type DatasetFilter struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+type DatasetFilters struct {
+	Match   string           `json:"match"`
+	Filters []*DatasetFilter `json:"filters"`
+}
+
+type DatasetQuery struct {
+	Fields  []string        `json:"fields"`
+	Filters *DatasetFilters `json:"filters,omitempty"`
+}
+
+type DatasetPagination struct {
+	CurrentPage int    `json:"current_page"`
+	TotalPages  int    `json:"total_pages"`
+	NextPage    string `json:"next_page"`
+}
+
+type DatasetResults struct {
+	Data       []map[string]interface{} `json:"data"`
+	Pagination DatasetPagination        `json:"pagination"`
+}
+
+// datasetSource lists employees from the employee dataset, which filters on
+// the server and returns results a page at a time. Page tokens are dataset
+// page numbers.
+type datasetSource struct {
+	client   *BambooHRClient
+	pageSize int
+}
+
+func (s *datasetSource) ListEmployees(
+	ctx context.Context,
+	filters *ReportFilters,
+	fields []string,
+	page string,
+) ([]*User, string, *v2.RateLimitDescription, error) {
+	pageNumber := 1
+	if page != "" {
+		var err error
+		pageNumber, err = strconv.Atoi(page)
+		if err != nil || pageNumber < 1 {
+			return nil, "", nil, fmt.Errorf("invalid dataset page %q", page)
+		}
+	}
+
+	query := DatasetQuery{
+		Fields:  []string{datasetFieldName("id")},
+		Filters: datasetFilters(filters),
+	}
+	for _, field := range fields {
+		query.Fields = append(query.Fields, datasetFieldName(field))
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	v := url.Values{}
+	v.Set("page", strconv.Itoa(pageNumber))
+	v.Set("page_size", strconv.Itoa(s.pageSize))
+	reqURL := s.client.newUnPaginatedURL(DatasetsEmployeeUrlPath, v)
+
+	results := &DatasetResults{}
+	ratelimitData, err := s.client.makeRequest(
+		ctx,
+		reqURL,
+		results,
+		http.MethodPost,
+		strings.NewReader(string(queryBytes)),
+	)
+	if err != nil {
+		return nil, "", ratelimitData, err
+	}
+
+	users := make([]*User, 0, len(results.Data))
+	for _, row := range results.Data {
+		user, err := datasetUser(row, fields)
+		if err != nil {
+			return nil, "", ratelimitData, err
+		}
+		users = append(users, user)
+	}
+
+	if results.Pagination.NextPage == "" || pageNumber >= results.Pagination.TotalPages {
+		return users, "", ratelimitData, nil
+	}
+	return users, strconv.Itoa(pageNumber + 1), ratelimitData, nil
+}
+
+func datasetFieldName(field string) string {
+	if name, ok := datasetFieldNames[field]; ok {
+		return name
+	}
+	return field
+}
+
+// datasetFilters translates report filters into their dataset equivalent.
+func datasetFilters(filters *ReportFilters) *DatasetFilters {
+	if filters == nil {
+		return nil
+	}
+	rv := &DatasetFilters{Match: "all"}
+	if filters.LastChanged != nil {
+		rv.Filters = append(rv.Filters, &DatasetFilter{
+			Field:    "lastChanged",
+			Operator: "gte",
+			Value:    filters.LastChanged.Value,
+		})
+	}
+	if filters.Status != "" {
+		rv.Filters = append(rv.Filters, &DatasetFilter{
+			Field:    "status",
+			Operator: "equal",
+			Value:    filters.Status,
+		})
+	}
+	if len(rv.Filters) == 0 {
+		return nil
+	}
+	return rv
+}
+
+// datasetUser renames a dataset row's fields back to their report aliases and
+// decodes it the same way a report row is decoded.
+func datasetUser(row map[string]interface{}, fields []string) (*User, error) {
+	renamed := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		renamed[key] = value
+	}
+	for _, field := range append([]string{"id"}, fields...) {
+		name := datasetFieldName(field)
+		if value, ok := row[name]; ok && name != field {
+			renamed[field] = value
+			delete(renamed, name)
+		}
+	}
+
+	data, err := json.Marshal(renamed)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{}
+	err = json.Unmarshal(data, user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
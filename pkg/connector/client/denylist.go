@@ -0,0 +1,94 @@
+package client
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ErrSensitiveField is returned when a request would read a denied field or
+// table while the denylist is enforced.
+var ErrSensitiveField = errors.New("sensitive field denied")
+
+// DeniedFields are employee fields the client refuses to request unless
+// sensitive fields have been allowed: national ids, compensation, bank details
+// and dates of birth. Names are matched case-insensitively.
+var DeniedFields = []string{
+	"ssn",
+	"sin",
+	"nin",
+	"nationalId",
+	"payRate",
+	"payType",
+	"payPer",
+	"paySchedule",
+	"payChangeReason",
+	"salary",
+	"compensation",
+	"bonusAmount",
+	"commissionAmount",
+	"bankName",
+	"bankAccount",
+	"accountNumber",
+	"routingNumber",
+	"iban",
+	"dateOfBirth",
+	"birthDate",
+}
+
+// DeniedFieldTypes are /meta/fields types that hold the same kind of data as
+// DeniedFields. They catch custom fields, which have no well-known alias.
+var DeniedFieldTypes = []string{
+	"ssn",
+	"sin",
+	"nin",
+	"currency",
+}
+
+// DeniedTables are employee tables that hold the same kind of data as
+// DeniedFields.
+var DeniedTables = []string{
+	"compensation",
+	"bonus",
+	"commission",
+	"directDeposit",
+}
+
+// IsDeniedField reports whether a field is in DeniedFields.
+func IsDeniedField(field string) bool {
+	return containsFold(DeniedFields, field)
+}
+
+// IsDeniedFieldType reports whether a /meta/fields type is in
+// DeniedFieldTypes.
+func IsDeniedFieldType(fieldType string) bool {
+	return containsFold(DeniedFieldTypes, fieldType)
+}
+
+// IsDeniedTable reports whether a table is in DeniedTables.
+func IsDeniedTable(table string) bool {
+	return containsFold(DeniedTables, table)
+}
+
+// jsonFields returns the JSON names of a struct's fields.
+func jsonFields(model interface{}) []string {
+	modelType := reflect.TypeOf(model)
+	rv := make([]string, 0, modelType.NumField())
+	for i := 0; i < modelType.NumField(); i++ {
+		name, _, _ := strings.Cut(modelType.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = modelType.Field(i).Name
+		}
+		rv = append(rv, name)
+	}
+	return rv
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
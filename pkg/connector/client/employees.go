@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+const (
+	DataSourceCustomReport = "custom-report"
+	DataSourceDatasets     = "datasets"
+)
+
+// EmployeeSource is a backend that lists employees with the requested report
+// fields a page at a time. The first page is "", and each page returns the
+// token of the next, or "" after the last. Every backend returns fields keyed
+// by their report alias, so they can be swapped without the syncers noticing.
+type EmployeeSource interface {
+	ListEmployees(
+		ctx context.Context,
+		filters *ReportFilters,
+		fields []string,
+		page string,
+	) ([]*User, string, *v2.RateLimitDescription, error)
+}
+
+var (
+	_ EmployeeSource = (*customReportSource)(nil)
+	_ EmployeeSource = (*datasetSource)(nil)
+)
+
+// customReportSource lists employees with a custom report. Reports are not
+// paginated, so every employee is on the first page, and only support
+// filtering on lastChanged, so a status filter is left to the caller.
+type customReportSource struct {
+	client *BambooHRClient
+}
+
+func (s *customReportSource) ListEmployees(
+	ctx context.Context,
+	filters *ReportFilters,
+	fields []string,
+	page string,
+) ([]*User, string, *v2.RateLimitDescription, error) {
+	if page != "" {
+		return nil, "", nil, fmt.Errorf("custom reports are not paginated, got page %q", page)
+	}
+	users := &ReportUserResults{}
+	v := url.Values{}
+	v.Set("format", "json")
+	reqURL := s.client.newUnPaginatedURL(UsersListUrlPath, v)
+
+	listUsersReqBody := ReqFields{
+		Title:   "ConductorOne Employees List Report",
+		Filters: filters,
+		Fields:  fields,
+	}
+	bodyBytes, err := json.Marshal(listUsersReqBody)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	body := strings.NewReader(string(bodyBytes))
+
+	ratelimitData, err := s.client.makeRequest(
+		ctx,
+		reqURL,
+		users,
+		http.MethodPost,
+		body,
+	)
+	if err != nil {
+		return nil, "", ratelimitData, err
+	}
+	return users.Users, "", ratelimitData, nil
+}
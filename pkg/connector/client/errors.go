@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorMessageHeader is the header BambooHR explains failed requests in.
+const ErrorMessageHeader = "X-BambooHR-Error-Message"
+
+// Errors that a RequestError or NetworkError matches with errors.Is, by the
+// kind of failure.
+var (
+	ErrBadRequest       = errors.New("bad request")
+	ErrUnauthenticated  = errors.New("invalid API key")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNotFound         = errors.New("not found")
+	ErrUnavailable      = errors.New("BambooHR unavailable")
+	ErrUnexpectedStatus = errors.New("unexpected status")
+)
+
+// RequestError is a request that BambooHR answered with an error status. It
+// carries the gRPC code matching its kind, so the SDK only retries the
+// failures that are worth retrying.
+type RequestError struct {
+	Status int
+	URL    *url.URL
+	// Message is BambooHR's explanation of the error, when it gave one.
+	Message string
+	// Body is the response body, redacted with RedactBody. It is kept out of
+	// Error, since a body can hold employee data that redaction misses.
+	Body string
+}
+
+func (r *RequestError) Error() string {
+	detail := r.Message
+	if detail == "" {
+		detail = http.StatusText(r.Status)
+	}
+	return fmt.Sprintf(
+		"bamboohr-connector: request error. Status: %d, Url: %s, Error: %s",
+		r.Status,
+		r.URL,
+		detail,
+	)
+}
+
+// Unwrap returns the sentinel error for the kind of failure.
+func (r *RequestError) Unwrap() error {
+	switch {
+	case r.Status == http.StatusBadRequest:
+		return ErrBadRequest
+	case r.Status == http.StatusUnauthorized:
+		return ErrUnauthenticated
+	case r.Status == http.StatusForbidden:
+		return ErrPermissionDenied
+	case r.Status == http.StatusNotFound:
+		return ErrNotFound
+	case r.Status == http.StatusTooManyRequests, r.Status >= http.StatusInternalServerError:
+		return ErrUnavailable
+	default:
+		return ErrUnexpectedStatus
+	}
+}
+
+// Code returns the gRPC code for the kind of failure.
+func (r *RequestError) Code() codes.Code {
+	switch r.Unwrap() {
+	case ErrBadRequest:
+		return codes.InvalidArgument
+	case ErrUnauthenticated:
+		return codes.Unauthenticated
+	case ErrPermissionDenied:
+		return codes.PermissionDenied
+	case ErrNotFound:
+		return codes.NotFound
+	case ErrUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+func (r *RequestError) GRPCStatus() *status.Status {
+	return status.New(r.Code(), r.Error())
+}
+
+// NetworkError is a request that got no response from BambooHR. It is always
+// retryable.
+type NetworkError struct {
+	URL *url.URL
+	Err error
+}
+
+func (n *NetworkError) Error() string {
+	return fmt.Sprintf("bamboohr-connector: network error. Url: %s, Error: %s", n.URL, n.Err)
+}
+
+func (n *NetworkError) Unwrap() []error {
+	return []error{ErrUnavailable, n.Err}
+}
+
+func (n *NetworkError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, n.Error())
+}
+
+// networkError classifies an error returned without a response. Errors caused
+// by the caller's context are returned unchanged, since retrying them cannot
+// succeed.
+func networkError(ctx context.Context, url *url.URL, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return &NetworkError{URL: url, Err: err}
+}
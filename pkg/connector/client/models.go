@@ -1,5 +1,13 @@
 package client
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
 type User struct {
 	Id              string `json:"id"`
 	FirstName       string `json:"firstName"`
@@ -10,6 +18,125 @@ type User struct {
 	SupervisorEmail string `json:"supervisorEmail"`
 	Email           string `json:"workEmail"`
 	Status          string `json:"status"`
+	HireDate        string `json:"hireDate"`
+	TerminationDate string `json:"terminationDate"`
+	// EmploymentStatus is the employee's current employment status, such as
+	// "Full-Time" or "Contractor".
+	EmploymentStatus string `json:"employmentHistoryStatus"`
+	// Fields holds every field returned by the report, including any extra
+	// fields that were requested, keyed by the name they were requested with.
+	Fields map[string]string `json:"-"`
+}
+
+func (u *User) UnmarshalJSON(data []byte) error {
+	type user User
+	err := json.Unmarshal(data, (*user)(u))
+	if err != nil {
+		return err
+	}
+
+	raw := make(map[string]interface{})
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	u.Fields = make(map[string]string, len(raw))
+	for key, value := range raw {
+		if value == nil {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			u.Fields[key] = s
+			continue
+		}
+		u.Fields[key] = fmt.Sprint(value)
+	}
+	return nil
+}
+
+// UserStatusActive is the status of currently employed employees.
+const UserStatusActive = "Active"
+
+// IsActive reports whether the employee is currently employed.
+func (u *User) IsActive() bool {
+	return strings.EqualFold(u.Status, UserStatusActive)
+}
+
+// HasPhoto reports whether a photo has been uploaded for the employee.
+func (u *User) HasPhoto() bool {
+	return strings.EqualFold(u.Fields["photoUploaded"], "true")
+}
+
+// FlexibleString decodes a JSON string or number into a string, since
+// BambooHR returns ids as either depending on the endpoint.
+type FlexibleString string
+
+func (f *FlexibleString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*f = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return err
+		}
+		*f = FlexibleString(s)
+		return nil
+	}
+	var n json.Number
+	err := json.Unmarshal(data, &n)
+	if err != nil {
+		return err
+	}
+	*f = FlexibleString(n.String())
+	return nil
+}
+
+func (f FlexibleString) String() string {
+	return string(f)
+}
+
+// ListOption is one of the values a list field can take.
+type ListOption struct {
+	Id           FlexibleString `json:"id"`
+	Archived     string         `json:"archived"`
+	ArchivedDate string         `json:"archivedDate"`
+	Name         string         `json:"name"`
+}
+
+// IsArchived reports whether the option has been archived in BambooHR.
+func (o *ListOption) IsArchived() bool {
+	return strings.EqualFold(o.Archived, "yes")
+}
+
+// Field is an employee field as returned by /meta/fields. Fields without an
+// alias can only be requested by id.
+type Field struct {
+	Id    FlexibleString `json:"id"`
+	Name  string         `json:"name"`
+	Type  string         `json:"type"`
+	Alias string         `json:"alias"`
+}
+
+// ListField is a list-type field and its options, as returned by /meta/lists.
+type ListField struct {
+	FieldId    FlexibleString `json:"fieldId"`
+	Alias      string         `json:"alias"`
+	Manageable string         `json:"manageable"`
+	Multiple   string         `json:"multiple"`
+	Name       string         `json:"name"`
+	Options    []*ListOption  `json:"options"`
+}
+
+// ReportField returns the name the field should be requested with in a
+// custom report. Fields without an alias are requested by id.
+func (f *ListField) ReportField() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.FieldId.String()
 }
 
 type Fields struct {
@@ -19,8 +146,25 @@ type Fields struct {
 }
 
 type ReqFields struct {
-	Title  string   `json:"title"`
-	Fields []string `json:"fields"`
+	Title   string         `json:"title"`
+	Filters *ReportFilters `json:"filters,omitempty"`
+	Fields  []string       `json:"fields"`
+}
+
+// ReportFilters are filters BambooHR applies to a custom report.
+type ReportFilters struct {
+	LastChanged *ReportLastChangedFilter `json:"lastChanged,omitempty"`
+	// Status limits the employees to those with this status, e.g. "Active".
+	// Custom reports cannot filter on status, so only the Datasets API
+	// applies it and callers must still check it.
+	Status string `json:"-"`
+}
+
+// ReportLastChangedFilter limits a report to employees changed since Value,
+// an ISO 8601 timestamp.
+type ReportLastChangedFilter struct {
+	IncludeNull string `json:"includeNull"`
+	Value       string `json:"value"`
 }
 
 type ReportUserResults struct {
@@ -28,3 +172,137 @@ type ReportUserResults struct {
 	Fields []Fields `json:"fields"`
 	Users  []*User  `json:"employees"`
 }
+
+// CompanyBenefit is a benefit plan offered by the company.
+type CompanyBenefit struct {
+	Id                   string `json:"id"`
+	CompanyBenefitTypeId string `json:"companyBenefitTypeId"`
+	BenefitName          string `json:"benefitName"`
+	StartDate            string `json:"startDate"`
+	EndDate              string `json:"endDate"`
+}
+
+// EmployeeBenefit is an employee's enrollment in a company benefit. Cost and
+// deduction amounts are intentionally not decoded.
+type EmployeeBenefit struct {
+	EmployeeId        string `json:"employeeId"`
+	CompanyBenefitId  string `json:"companyBenefitId"`
+	CoverageStartDate string `json:"coverageStartDate"`
+	CoverageEndDate   string `json:"coverageEndDate"`
+	EnrollmentStatus  string `json:"enrollmentStatus"`
+}
+
+type CompanyInformation struct {
+	LegalName   string `json:"legalName"`
+	DisplayName string `json:"displayName"`
+}
+
+// LoginUser is a BambooHR login account from /meta/users.
+type LoginUser struct {
+	Id         FlexibleString `json:"id"`
+	EmployeeId FlexibleString `json:"employeeId"`
+	FirstName  string         `json:"firstName"`
+	LastName   string         `json:"lastName"`
+	Email      string         `json:"email"`
+	Status     string         `json:"status"`
+	LastLogin  string         `json:"lastLogin"`
+}
+
+// IsEnabled reports whether the account can currently log in.
+func (u *LoginUser) IsEnabled() bool {
+	return strings.EqualFold(u.Status, "enabled")
+}
+
+// LastLoginTime parses LastLogin. It returns false if the account has never
+// logged in.
+func (u *LoginUser) LastLoginTime() (time.Time, bool) {
+	if u.LastLogin == "" {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(time.RFC3339, u.LastLogin)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// TableRow is a single row of a BambooHR employee table, keyed by field alias.
+type TableRow map[string]interface{}
+
+// Get returns the string form of a field, or an empty string when the field is
+// missing or null.
+func (r TableRow) Get(field string) string {
+	value, ok := r[field]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// EmployeeId returns the id of the employee the row belongs to.
+func (r TableRow) EmployeeId() string {
+	return r.Get("employeeId")
+}
+
+// EffectiveDate returns the date an effective-dated row, such as a jobInfo or
+// employmentStatus row, takes effect.
+func (r TableRow) EffectiveDate() (time.Time, bool) {
+	return ParseDate(r.Get("date"))
+}
+
+type ChangedTableEmployee struct {
+	LastChanged string     `json:"lastChanged"`
+	Rows        []TableRow `json:"rows"`
+}
+
+type ChangedTableResults struct {
+	Table     string                           `json:"table"`
+	Employees map[string]*ChangedTableEmployee `json:"employees"`
+}
+
+// Rows flattens the per-employee rows, ordered by employee id so results are
+// stable. Rows missing an employeeId get the id they were grouped under.
+func (r *ChangedTableResults) Rows() []TableRow {
+	employeeIds := make([]string, 0, len(r.Employees))
+	for employeeId := range r.Employees {
+		employeeIds = append(employeeIds, employeeId)
+	}
+	sort.Strings(employeeIds)
+
+	rv := make([]TableRow, 0)
+	for _, employeeId := range employeeIds {
+		employee := r.Employees[employeeId]
+		if employee == nil {
+			continue
+		}
+		for _, row := range employee.Rows {
+			if row == nil {
+				continue
+			}
+			if row.EmployeeId() == "" {
+				row["employeeId"] = employeeId
+			}
+			rv = append(rv, row)
+		}
+	}
+	return rv
+}
+
+// DateLayout is the format BambooHR uses for date fields.
+const DateLayout = "2006-01-02"
+
+// ParseDate parses a BambooHR date. BambooHR reports unset dates as either an
+// empty string or "0000-00-00", in which case false is returned.
+func ParseDate(value string) (time.Time, bool) {
+	if value == "" || value == "0000-00-00" {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultConcurrency is how many per-employee requests a Pool sends at once
+// unless configured otherwise. BambooHR does not publish its rate limits, so
+// it is kept low.
+const DefaultConcurrency = 4
+
+const (
+	// poolAttempts is how many times a Pool sends a request that BambooHR
+	// rate limits or is unavailable for before giving up on it.
+	poolAttempts = 3
+	// poolBackoff is the first wait before a retry when BambooHR does not
+	// send a Retry-After header. It doubles with each attempt.
+	poolBackoff = time.Second
+)
+
+// Pool runs per-employee requests, such as employee tables and photos, with
+// bounded concurrency. One Pool is meant to be shared by every syncer, so the
+// bound holds across syncers that fetch at the same time.
+//
+// A request that is rate limited pauses the whole pool until BambooHR's
+// Retry-After, or an exponential backoff without one, and is then retried.
+type Pool struct {
+	slots    chan struct{}
+	attempts int
+	backoff  time.Duration
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// NewPool returns a Pool that sends at most concurrency requests at once.
+func NewPool(concurrency int) (*Pool, error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("bambooHR-client: pool concurrency must be at least 1, got %d", concurrency)
+	}
+	return &Pool{
+		slots:    make(chan struct{}, concurrency),
+		attempts: poolAttempts,
+		backoff:  poolBackoff,
+	}, nil
+}
+
+// Concurrency returns the most requests the pool sends at once.
+func (p *Pool) Concurrency() int {
+	return cap(p.slots)
+}
+
+// EmployeeResults is the outcome of ForEachEmployee.
+type EmployeeResults[T any] struct {
+	// Values holds the result of each employee whose request succeeded.
+	Values map[string]T
+	// Errors holds each employee whose request failed for a reason that
+	// retrying cannot fix, such as a 403 or 404.
+	Errors map[string]error
+	// RateLimit is the rate limit data of the last response.
+	RateLimit *v2.RateLimitDescription
+}
+
+// ForEachEmployee calls fetch for every employee on the pool. An employee
+// whose request fails is recorded in Errors and the rest carry on, so one bad
+// employee does not fail a whole page.
+//
+// An error is returned only when ctx is done, or when a request is still rate
+// limited or unavailable after the pool's retries. That error keeps its
+// codes.Unavailable status, so the SDK retries the page later, and the
+// outstanding requests are cancelled.
+func ForEachEmployee[T any](
+	ctx context.Context,
+	pool *Pool,
+	employeeIds []string,
+	fetch func(ctx context.Context, employeeId string) (T, *v2.RateLimitDescription, error),
+) (*EmployeeResults[T], error) {
+	rv := &EmployeeResults[T]{
+		Values: make(map[string]T, len(employeeIds)),
+		Errors: make(map[string]error),
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed error
+	)
+	for _, employeeId := range employeeIds {
+		if !pool.acquire(workerCtx) {
+			break
+		}
+		wg.Add(1)
+		go func(employeeId string) {
+			defer wg.Done()
+			defer pool.release()
+
+			var value T
+			ratelimitData, err := pool.do(workerCtx, func(ctx context.Context) (*v2.RateLimitDescription, error) {
+				var (
+					ratelimitData *v2.RateLimitDescription
+					err           error
+				)
+				value, ratelimitData, err = fetch(ctx, employeeId)
+				return ratelimitData, err
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if ratelimitData != nil {
+				rv.RateLimit = ratelimitData
+			}
+			switch {
+			case err == nil:
+				rv.Values[employeeId] = value
+			case workerCtx.Err() != nil:
+				// Cancelled because ctx is done or another employee failed.
+			case isRetryable(err):
+				failed = err
+				cancel()
+			default:
+				rv.Errors[employeeId] = err
+			}
+		}(employeeId)
+	}
+	wg.Wait()
+
+	if failed != nil {
+		return rv, failed
+	}
+	return rv, ctx.Err()
+}
+
+// Do sends a single request on the pool, for per-employee reads that are not
+// batched, such as an employee's photo. It waits for a free slot and retries
+// the request like ForEachEmployee does.
+func (p *Pool) Do(
+	ctx context.Context,
+	request func(ctx context.Context) (*v2.RateLimitDescription, error),
+) (*v2.RateLimitDescription, error) {
+	if !p.acquire(ctx) {
+		return nil, ctx.Err()
+	}
+	defer p.release()
+	return p.do(ctx, request)
+}
+
+// acquire waits for a free slot. It returns false when ctx is done first.
+func (p *Pool) acquire(ctx context.Context) bool {
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *Pool) release() {
+	<-p.slots
+}
+
+// do sends a request once the pool is not paused, retrying it while it is rate
+// limited or BambooHR is unavailable.
+func (p *Pool) do(
+	ctx context.Context,
+	request func(ctx context.Context) (*v2.RateLimitDescription, error),
+) (*v2.RateLimitDescription, error) {
+	backoff := p.backoff
+	for attempt := 1; ; attempt++ {
+		err := p.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ratelimitData, err := request(ctx)
+		if err == nil || !isRetryable(err) || attempt >= p.attempts {
+			return ratelimitData, err
+		}
+		p.pause(retryAt(ratelimitData, backoff))
+		backoff *= 2
+	}
+}
+
+// wait blocks until the pool is no longer paused or ctx is done.
+func (p *Pool) wait(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		delay := time.Until(p.pausedUntil)
+		p.mu.Unlock()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// pause holds every request of the pool until the given time.
+func (p *Pool) pause(until time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until.After(p.pausedUntil) {
+		p.pausedUntil = until
+	}
+}
+
+// retryAt returns BambooHR's Retry-After when it sent one, or the backoff
+// from now otherwise.
+func retryAt(ratelimitData *v2.RateLimitDescription, backoff time.Duration) time.Time {
+	if ratelimitData.GetResetAt() != nil {
+		return ratelimitData.GetResetAt().AsTime()
+	}
+	return time.Now().Add(backoff)
+}
+
+// isRetryable reports whether a request failed because it was rate limited or
+// BambooHR was unavailable, which a later attempt can fix.
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrUnavailable) || status.Code(err) == codes.Unavailable
+}
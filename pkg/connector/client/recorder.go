@@ -0,0 +1,279 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// CaptureVersion is the format version written to capture files. Version 1
+// captures, a single JSON document, are still replayed.
+const CaptureVersion = 2
+
+// piiFields are the JSON keys whose values are pseudonymized in captures,
+// keyed by lower-cased name, with the kind of placeholder to substitute.
+var piiFields = map[string]string{
+	"firstname":       "text",
+	"middlename":      "text",
+	"lastname":        "text",
+	"preferredname":   "text",
+	"legalfirstname":  "text",
+	"legalmiddlename": "text",
+	"legallastname":   "text",
+	"nickname":        "text",
+	"displayname":     "text",
+	"fullname":        "text",
+	"legalname":       "text",
+	"companyname":     "text",
+	"supervisor":      "text",
+	"reportsto":       "text",
+	"email":           "email",
+	"workemail":       "email",
+	"homeemail":       "email",
+	"supervisoremail": "email",
+	"workphone":       "text",
+	"mobilephone":     "text",
+	"homephone":       "text",
+	"address1":        "text",
+	"address2":        "text",
+	"city":            "text",
+	"zipcode":         "text",
+	"dateofbirth":     "date",
+	"ssn":             "text",
+	"sin":             "text",
+	"nin":             "text",
+	"gender":          "text",
+	"maritalstatus":   "text",
+	"ethnicity":       "text",
+	// Free-text table columns, which can mention anyone.
+	"comment":     "text",
+	"comments":    "text",
+	"note":        "text",
+	"notes":       "text",
+	"description": "text",
+	"reason":      "text",
+}
+
+// placeholderPhoto replaces recorded images, which are usually employee
+// photos. It is a PNG file signature.
+var placeholderPhoto = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// Interaction is a recorded request and the response BambooHR gave to it.
+// Paths are relative to the company's API root, so captures do not reveal the
+// company domain and can be replayed against any domain.
+type Interaction struct {
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	Query        string          `json:"query,omitempty"`
+	RequestBody  json.RawMessage `json:"requestBody,omitempty"`
+	Status       int             `json:"status"`
+	ContentType  string          `json:"contentType,omitempty"`
+	RetryAfter   string          `json:"retryAfter,omitempty"`
+	ResponseJSON json.RawMessage `json:"responseJson,omitempty"`
+	ResponseData []byte          `json:"responseData,omitempty"`
+}
+
+// Capture is the header of a capture file. A capture is a stream of JSON
+// values, one per line: the header, then each Interaction in the order it
+// happened. Version 1 captures were a single Capture with Interactions set.
+type Capture struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions,omitempty"`
+}
+
+// RecordingTransport passes requests through to BambooHR and writes every
+// exchange to a capture file. Credentials are never recorded, and personal
+// data in JSON responses is replaced with pseudonyms that stay consistent
+// within one capture, so records still join up. Images and other responses
+// that are not JSON are replaced with placeholders.
+type RecordingTransport struct {
+	next http.RoundTripper
+	salt []byte
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecordingTransport creates the capture file at path, replacing any file
+// already there, and writes its header.
+func NewRecordingTransport(next http.RoundTripper, path string) (*RecordingTransport, error) {
+	salt := make([]byte, 32)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("bambooHR-client: error creating capture %w", err)
+	}
+	t := &RecordingTransport{
+		next: next,
+		salt: salt,
+		file: file,
+	}
+	err = t.writeLine(&Capture{Version: CaptureVersion})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *RecordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+		request.Body.Close()
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := &Interaction{
+		Method:      request.Method,
+		Path:        capturePath(request),
+		Query:       request.URL.RawQuery,
+		RequestBody: compactJSON(requestBody),
+		Status:      response.StatusCode,
+		ContentType: response.Header.Get("Content-Type"),
+		RetryAfter:  response.Header.Get("Retry-After"),
+	}
+	switch {
+	case strings.HasPrefix(interaction.ContentType, "image/"):
+		interaction.ResponseData = placeholderPhoto
+	case json.Valid(responseBody):
+		interaction.ResponseJSON, err = t.pseudonymizeJSON(responseBody)
+		if err != nil {
+			return nil, err
+		}
+	case len(responseBody) > 0:
+		// Other bodies, such as XML or HTML error pages, cannot be
+		// pseudonymized field by field, so only a placeholder is kept.
+		interaction.ResponseData = []byte(RedactBody(responseBody))
+	}
+
+	err = t.record(interaction)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// record appends an interaction to the capture file. Each interaction is
+// written as soon as it happens, so a capture survives the connector exiting
+// mid-sync.
+func (t *RecordingTransport) record(interaction *Interaction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.writeLine(interaction)
+}
+
+func (t *RecordingTransport) writeLine(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = t.file.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("bambooHR-client: error writing capture %w", err)
+	}
+	return nil
+}
+
+// Close closes the capture file.
+func (t *RecordingTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.file.Close()
+}
+
+func (t *RecordingTransport) pseudonymizeJSON(data []byte) (json.RawMessage, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(t.pseudonymize("", value))
+}
+
+func (t *RecordingTransport) pseudonymize(key string, value interface{}) interface{} {
+	if _, ok := piiFields[strings.ToLower(key)]; !ok && IsSensitiveField(key) && value != nil {
+		return Redacted
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for childKey, child := range v {
+			v[childKey] = t.pseudonymize(childKey, child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = t.pseudonymize(key, child)
+		}
+		return v
+	case string:
+		kind, ok := piiFields[strings.ToLower(key)]
+		if !ok || v == "" {
+			return v
+		}
+		mac := hmac.New(sha256.New, t.salt)
+		mac.Write([]byte(strings.ToLower(v)))
+		pseudonym := hex.EncodeToString(mac.Sum(nil))[:10]
+		switch kind {
+		case "email":
+			return "user-" + pseudonym + "@example.com"
+		case "date":
+			return "1970-01-01"
+		default:
+			return key + "-" + pseudonym
+		}
+	default:
+		return v
+	}
+}
+
+// capturePath returns a request path relative to the company's API root.
+func capturePath(request *http.Request) string {
+	_, path, ok := strings.Cut(request.URL.Path, "/"+APIVersion+"/")
+	if !ok {
+		return request.URL.Path
+	}
+	return path
+}
+
+// compactJSON normalizes a JSON request body so that it can be matched on
+// replay. Non-JSON bodies are dropped.
+func compactJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	buffer := &bytes.Buffer{}
+	if json.Compact(buffer, data) != nil {
+		return nil
+	}
+	return buffer.Bytes()
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// Redacted replaces sensitive values in logs and errors.
+	Redacted = "[REDACTED]"
+	// MaxRedactedBodyLength is how much of a body is kept in logs and errors.
+	MaxRedactedBodyLength = 1024
+)
+
+// personalFields are home addresses and personal contact details. Unlike
+// DeniedFields they may be synced, but their values are never logged or
+// returned in errors either.
+var personalFields = []string{
+	"address1",
+	"address2",
+	"city",
+	"state",
+	"zipcode",
+	"country",
+	"homeEmail",
+	"homePhone",
+	"mobilePhone",
+}
+
+// SensitiveFields are BambooHR fields whose values are never logged or
+// returned in errors: every field in DeniedFields, plus personalFields. Names
+// are matched case-insensitively.
+var SensitiveFields = append(slices.Clone(DeniedFields), personalFields...)
+
+// IsSensitiveField reports whether a field is in SensitiveFields.
+func IsSensitiveField(field string) bool {
+	return containsFold(SensitiveFields, field)
+}
+
+// RedactBody masks sensitive fields in a JSON body and truncates it to
+// MaxRedactedBodyLength, so it can be logged. Bodies that are not JSON, such
+// as XML or HTML error pages, cannot be checked field by field and are masked
+// entirely.
+func RedactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&value) != nil || decoder.More() {
+		return fmt.Sprintf("%s (%d bytes, not JSON)", Redacted, len(body))
+	}
+	redacted, err := json.Marshal(redactValue("", value))
+	if err != nil {
+		return fmt.Sprintf("%s (%d bytes)", Redacted, len(body))
+	}
+	body = redacted
+
+	if len(body) <= MaxRedactedBodyLength {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", body[:MaxRedactedBodyLength], len(body)-MaxRedactedBodyLength)
+}
+
+func redactValue(key string, value interface{}) interface{} {
+	if key != "" && IsSensitiveField(key) && value != nil {
+		return Redacted
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for childKey, child := range v {
+			v[childKey] = redactValue(childKey, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue("", child)
+		}
+	}
+	return value
+}
+
+// redactHeaders returns headers for logging, without credentials.
+func redactHeaders(header http.Header) map[string]string {
+	rv := make(map[string]string, len(header))
+	for name, values := range header {
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization", "Cookie", "Set-Cookie":
+			rv[name] = Redacted
+		default:
+			rv[name] = strings.Join(values, ", ")
+		}
+	}
+	return rv
+}
+
+// bodyLoggingTransport logs requests and responses with their bodies at debug
+// level, after redacting them.
+type bodyLoggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *bodyLoggingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	l := ctxzap.Extract(request.Context())
+
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+		request.Body.Close()
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	l.Debug(
+		"BambooHR request",
+		zap.String("http.method", request.Method),
+		zap.String("http.url_details.path", request.URL.Path),
+		zap.Any("http.headers", redactHeaders(request.Header)),
+		zap.String("http.body", RedactBody(requestBody)),
+	)
+
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	l.Debug(
+		"BambooHR response",
+		zap.String("http.method", request.Method),
+		zap.String("http.url_details.path", request.URL.Path),
+		zap.Int("http.status_code", response.StatusCode),
+		zap.Any("http.headers", redactHeaders(response.Header)),
+		zap.String("http.body", RedactBody(responseBody)),
+	)
+	return response, nil
+}
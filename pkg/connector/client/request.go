@@ -2,7 +2,6 @@ package client
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -20,23 +19,11 @@ const (
 	APIGateway                = "gateway.php"
 	APIVersion                = "v1"
 	BambooPasswordPlaceholder = "x"
+	// JSONContentType must be sent as Accept: without it BambooHR answers
+	// most endpoints, such as /meta and employee tables, with XML.
+	JSONContentType = "application/json"
 )
 
-type RequestError struct {
-	Status int
-	URL    *url.URL
-	Body   string
-}
-
-func (r *RequestError) Error() string {
-	return fmt.Sprintf(
-		"bamboohr-connector: request error. Status: %d, Url: %s, Body: %s",
-		r.Status,
-		r.URL,
-		r.Body,
-	)
-}
-
 func (c *BambooHRClient) newUnPaginatedURL(path string, v url.Values) *url.URL {
 	return &url.URL{
 		Scheme: c.BaseUrl.Scheme,
@@ -62,43 +49,85 @@ func (c *BambooHRClient) makeRequest(
 	method string,
 	requestBody io.Reader,
 ) (*v2.RateLimitDescription, error) {
+	_, ratelimitData, err := c.doRequest(
+		ctx,
+		url,
+		method,
+		requestBody,
+		uhttp.WithJSONResponse(target),
+	)
+	return ratelimitData, err
+}
+
+// makeRawRequest is makeRequest for endpoints that return binary content, such
+// as images. It returns the body and its content type.
+func (c *BambooHRClient) makeRawRequest(
+	ctx context.Context,
+	url *url.URL,
+) ([]byte, string, *v2.RateLimitDescription, error) {
+	response, ratelimitData, err := c.doRequest(
+		ctx,
+		url,
+		http.MethodGet,
+		nil,
+	)
+	if err != nil {
+		return nil, "", ratelimitData, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", ratelimitData, err
+	}
+	return body, response.Header.Get(uhttp.ContentType), ratelimitData, nil
+}
+
+func (c *BambooHRClient) doRequest(
+	ctx context.Context,
+	url *url.URL,
+	method string,
+	requestBody io.Reader,
+	options ...uhttp.DoOption,
+) (*http.Response, *v2.RateLimitDescription, error) {
 	req, err := http.NewRequestWithContext(ctx, method, url.String(), requestBody)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	req.SetBasicAuth(c.ApiKey, BambooPasswordPlaceholder)
+	req.Header.Set("Accept", JSONContentType)
 
 	ratelimitData := v2.RateLimitDescription{}
 
 	response, err := c.wrapper.Do(
 		req,
-		WithBambooHrRatelimitData(&ratelimitData),
-		uhttp.WithJSONResponse(target),
+		append([]uhttp.DoOption{WithBambooHrRatelimitData(&ratelimitData)}, options...)...,
 	)
 	if err == nil {
-		return &ratelimitData, nil
+		return response, &ratelimitData, nil
 	}
 	if response == nil {
-		return nil, err
+		return nil, nil, networkError(ctx, url, err)
 	}
 	defer response.Body.Close()
 
 	// If we get ratelimit data back (e.g. the "Retry-After" header) or a
 	// "ratelimit-like" status code, then return a recoverable gRPC code.
 	if isRatelimited(ratelimitData.Status, response.StatusCode) {
-		return &ratelimitData, status.Error(codes.Unavailable, response.Status)
+		return nil, &ratelimitData, status.Error(codes.Unavailable, response.Status)
 	}
 
 	// If it's some other error, it is unrecoverable.
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return nil, &RequestError{
-		URL:    url,
-		Status: response.StatusCode,
-		Body:   string(responseBody),
+	return nil, nil, &RequestError{
+		URL:     url,
+		Status:  response.StatusCode,
+		Message: response.Header.Get(ErrorMessageHeader),
+		Body:    RedactBody(responseBody),
 	}
 }
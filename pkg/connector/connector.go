@@ -1,40 +1,417 @@
 package connector
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 )
 
+const defaultPhotoSize = client.PhotoSizeSmall
+
 type BambooHr struct {
 	customerDomain string
-	client         *client.BambooHRClient
+	client         client.Client
 	apiKey         string
+	customTables   []*CustomTableMapping
+	groupByFields  []string
+	photoSize      string
+	userConfig     userConfig
+	// departmentDivisions maps lower-cased department names to division
+	// names, on top of the mapping inferred from employees.
+	departmentDivisions map[string]string
+	// jobChangeEvents enables the jobInfo event feed in ListEvents.
+	jobChangeEvents bool
+	// seniorManagerThreshold is the number of indirect reports a
+	// senior_manager must exceed. The role is not synced when it is zero.
+	seniorManagerThreshold int
+	// allowSensitiveFields turns off the client.DeniedFields and
+	// client.DeniedTables checks.
+	allowSensitiveFields bool
+	// pool runs the per-employee requests of every syncer.
+	pool *client.Pool
+	// directory holds the list fields and employees report shared by every
+	// syncer during a sync.
+	directory *directory
+}
+
+// Option configures optional connector behaviour.
+type Option func(*BambooHr) error
+
+// WithCustomTables adds a resource syncer for each custom table mapping.
+func WithCustomTables(mappings []*CustomTableMapping) Option {
+	return func(c *BambooHr) error {
+		c.customTables = append(c.customTables, mappings...)
+		return nil
+	}
+}
+
+// WithEmployeeConcurrency sets how many per-employee requests, such as employee
+// photos and reads of custom tables with per_employee set, are sent to
+// BambooHR at once. It
+// defaults to client.DefaultConcurrency.
+func WithEmployeeConcurrency(concurrency int) Option {
+	return func(c *BambooHr) error {
+		pool, err := client.NewPool(concurrency)
+		if err != nil {
+			return err
+		}
+		c.pool = pool
+		return nil
+	}
+}
+
+// WithGroupByFields adds a group resource syncer for each BambooHR list field.
+func WithGroupByFields(fields []string) Option {
+	return func(c *BambooHr) error {
+		for _, field := range fields {
+			if listFieldResourceTypeId(field) == "" {
+				return fmt.Errorf("bamboohr-connector: invalid group-by field %q", field)
+			}
+			c.groupByFields = append(c.groupByFields, field)
+		}
+		return nil
+	}
+}
+
+// WithPhotoSize sets the size of the employee photos served as user icons.
+func WithPhotoSize(size string) Option {
+	return func(c *BambooHr) error {
+		if !slices.Contains(client.PhotoSizes, size) {
+			return fmt.Errorf("bamboohr-connector: invalid photo size %q, expected one of %v", size, client.PhotoSizes)
+		}
+		c.photoSize = size
+		return nil
+	}
+}
+
+// WithAccountTypes maps employment statuses to account types. Each entry has
+// the form "<employment status>=<human|service|system>", e.g.
+// "Service Account=service".
+func WithAccountTypes(entries []string) Option {
+	return func(c *BambooHr) error {
+		accountTypes := make(map[string]v2.UserTrait_AccountType)
+		for _, entry := range entries {
+			employmentStatus, accountType, ok := strings.Cut(entry, "=")
+			employmentStatus = strings.ToLower(strings.TrimSpace(employmentStatus))
+			if !ok || employmentStatus == "" {
+				return fmt.Errorf("bamboohr-connector: invalid account type mapping %q", entry)
+			}
+			switch strings.ToLower(strings.TrimSpace(accountType)) {
+			case "human":
+				accountTypes[employmentStatus] = v2.UserTrait_ACCOUNT_TYPE_HUMAN
+			case "service":
+				accountTypes[employmentStatus] = v2.UserTrait_ACCOUNT_TYPE_SERVICE
+			case "system":
+				accountTypes[employmentStatus] = v2.UserTrait_ACCOUNT_TYPE_SYSTEM
+			default:
+				return fmt.Errorf("bamboohr-connector: invalid account type mapping %q", entry)
+			}
+		}
+		c.userConfig.accountTypes = accountTypes
+		return nil
+	}
+}
+
+// WithUserFilter only syncs employees matching a filter expression. See
+// UserFilter for the syntax.
+func WithUserFilter(expression string) Option {
+	return func(c *BambooHr) error {
+		filter, err := ParseUserFilter(expression)
+		if err != nil {
+			return err
+		}
+		c.userConfig.filter = filter
+		return nil
+	}
+}
+
+// WithTerminatedEmployees controls whether inactive employees are synced and,
+// if so, for how many days after their termination date. A retention of zero
+// keeps them indefinitely. Excluded employees are filtered out by BambooHR
+// when the data source supports it.
+func WithTerminatedEmployees(include bool, retentionDays int) Option {
+	return func(c *BambooHr) error {
+		if retentionDays < 0 {
+			return fmt.Errorf("bamboohr-connector: terminated retention days must not be negative")
+		}
+		c.userConfig.excludeTerminated = !include
+		c.userConfig.terminatedRetentionDays = retentionDays
+		return nil
+	}
+}
+
+// WithTerminatedStatus sets the status of synced inactive employees: "deleted",
+// the default, deletes those with a termination date and disables the rest,
+// "disabled" disables them all, and "enabled" keeps them enabled.
+func WithTerminatedStatus(terminatedStatus string) Option {
+	return func(c *BambooHr) error {
+		switch strings.ToLower(strings.TrimSpace(terminatedStatus)) {
+		case "":
+			c.userConfig.terminatedStatus = v2.UserTrait_Status_STATUS_UNSPECIFIED
+		case "enabled":
+			c.userConfig.terminatedStatus = v2.UserTrait_Status_STATUS_ENABLED
+		case "disabled":
+			c.userConfig.terminatedStatus = v2.UserTrait_Status_STATUS_DISABLED
+		case "deleted":
+			c.userConfig.terminatedStatus = v2.UserTrait_Status_STATUS_DELETED
+		default:
+			return fmt.Errorf("bamboohr-connector: invalid terminated status %q, expected deleted, disabled or enabled", terminatedStatus)
+		}
+		return nil
+	}
+}
+
+// WithoutFutureHires skips employees whose hire date is in the future instead
+// of syncing them as pending starts.
+func WithoutFutureHires() Option {
+	return func(c *BambooHr) error {
+		c.userConfig.excludeFutureHires = true
+		return nil
+	}
+}
+
+// WithDepartmentDivisions assigns departments to divisions with
+// <department>=<division> entries. Departments that are not listed are
+// assigned to a division when all of their employees are in it.
+func WithDepartmentDivisions(entries []string) Option {
+	return func(c *BambooHr) error {
+		departmentDivisions, err := ParseDepartmentDivisions(entries)
+		if err != nil {
+			return err
+		}
+		c.departmentDivisions = departmentDivisions
+		return nil
+	}
+}
+
+// WithSeniorManagerThreshold adds a senior_manager role, granted to active
+// employees with more than indirectReports active indirect reports.
+func WithSeniorManagerThreshold(indirectReports int) Option {
+	return func(c *BambooHr) error {
+		if indirectReports < 0 {
+			return fmt.Errorf("bamboohr-connector: senior manager indirect reports must not be negative")
+		}
+		c.seniorManagerThreshold = indirectReports
+		return nil
+	}
+}
+
+// WithPendingJobChanges adds each employee's next future-dated jobInfo and
+// employmentStatus changes to their profile as pending attributes.
+func WithPendingJobChanges() Option {
+	return func(c *BambooHr) error {
+		c.userConfig.pendingJobChanges = true
+		return nil
+	}
+}
+
+// WithEmploymentHistory adds each employee's full jobInfo and employmentStatus
+// history to their profile as employmentHistory, along with the hireDates it
+// implies and whether they were rehired.
+func WithEmploymentHistory() Option {
+	return func(c *BambooHr) error {
+		c.userConfig.employmentHistory = true
+		return nil
+	}
+}
+
+// WithJobChangeEvents reports hires, terminations and rehires, as grants and
+// revokes of the Employment group, and department and division changes from
+// jobInfo as grant and revoke events when they take effect. Only department
+// and division fields synced with WithGroupByFields produce events.
+func WithJobChangeEvents() Option {
+	return func(c *BambooHr) error {
+		c.jobChangeEvents = true
+		return nil
+	}
+}
+
+// WithOrgChartReport writes the org chart issues found on each sync to a JSON
+// validation report at path, in addition to logging them.
+func WithOrgChartReport(path string) Option {
+	return func(c *BambooHr) error {
+		c.userConfig.orgChartReport = path
+		return nil
+	}
+}
+
+// WithDataSource selects the BambooHR API employees are read from:
+// client.DataSourceCustomReport or client.DataSourceDatasets.
+func WithDataSource(dataSource string) Option {
+	return func(c *BambooHr) error {
+		httpClient, ok := c.client.(*client.BambooHRClient)
+		if !ok {
+			return fmt.Errorf("bamboohr-connector: data source %q requires the BambooHR HTTP client", dataSource)
+		}
+		return httpClient.SetDataSource(dataSource)
+	}
+}
+
+// WithClient replaces the BambooHR HTTP client, e.g. with test.ClientMock in
+// tests.
+func WithClient(bambooHRClient client.Client) Option {
+	return func(c *BambooHr) error {
+		c.client = bambooHRClient
+		return nil
+	}
+}
+
+// WithProfileFields copies extra BambooHR employee fields into each user's
+// profile, keyed by field name. Each entry is parsed with ParseProfileField,
+// so it can transform the value before it is synced.
+func WithProfileFields(entries []string) Option {
+	return func(c *BambooHr) error {
+		for _, entry := range entries {
+			field, err := ParseProfileField(entry)
+			if err != nil {
+				return err
+			}
+			if slices.Contains(c.userConfig.profileFieldNames(), field.Name) {
+				return fmt.Errorf("bamboohr-connector: profile field %q is listed more than once", field.Name)
+			}
+			c.userConfig.profileFields = append(c.userConfig.profileFields, field)
+		}
+		return nil
+	}
+}
+
+// WithProfileHashSalt sets the tenant secret that hash profile transforms are
+// keyed with. Hashes only join up across syncs that use the same salt.
+func WithProfileHashSalt(salt string) Option {
+	return func(c *BambooHr) error {
+		c.userConfig.profileHashSalt = []byte(salt)
+		return nil
+	}
+}
+
+// WithSensitiveFieldsAllowed lets the connector read fields and tables on the
+// client.DeniedFields and client.DeniedTables denylists, such as SSNs and
+// salaries. A warning is logged on every sync while it is set.
+func WithSensitiveFieldsAllowed() Option {
+	return func(c *BambooHr) error {
+		c.allowSensitiveFields = true
+		return nil
+	}
 }
 
 func New(
 	ctx context.Context,
 	customerDomain string,
 	apiKey string,
+	opts ...Option,
 ) (*BambooHr, error) {
-	client, err := client.New(ctx, apiKey, customerDomain)
+	bambooHRClient, err := client.New(ctx, apiKey, customerDomain)
 	if err != nil {
 		return nil, err
 	}
 	rv := &BambooHr{
 		customerDomain: customerDomain,
 		apiKey:         apiKey,
-		client:         client,
+		client:         bambooHRClient,
+		photoSize:      defaultPhotoSize,
+	}
+	for _, opt := range opts {
+		err := opt(rv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rv.pool == nil {
+		rv.pool, err = client.NewPool(client.DefaultConcurrency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = rv.checkResourceTypeIds()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range rv.userConfig.profileFields {
+		if field.hashed() && len(rv.userConfig.profileHashSalt) == 0 {
+			return nil, fmt.Errorf("bamboohr-connector: profile field %q is hashed, which requires a profile hash salt", field.Name)
+		}
+	}
+
+	rv.directory = newDirectory(rv.client, rv.pool, rv.userConfig, rv.groupByFields)
+
+	if rv.allowSensitiveFields {
+		httpClient, ok := rv.client.(*client.BambooHRClient)
+		if ok {
+			httpClient.AllowSensitiveFields()
+		}
+	} else {
+		err = rv.checkSensitiveFields()
+		if err != nil {
+			return nil, err
+		}
 	}
 	return rv, nil
 }
 
+// checkResourceTypeIds rejects custom tables and group-by fields whose
+// resource type id is already taken by a built-in resource type, a custom
+// table or another group-by field. Group-by ids are derived from field names,
+// so "User" or both "Cost Center" and "cost-center" would otherwise produce
+// syncers that overwrite each other.
+func (c *BambooHr) checkResourceTypeIds() error {
+	taken := make(map[string]string)
+	for _, resourceType := range builtInResourceTypes() {
+		taken[resourceType.Id] = "a built-in resource type"
+	}
+	for _, mapping := range c.customTables {
+		if owner, ok := taken[mapping.ResourceTypeId]; ok {
+			return fmt.Errorf("bamboohr-connector: custom table %q resource type %q conflicts with %s", mapping.Table, mapping.ResourceTypeId, owner)
+		}
+		taken[mapping.ResourceTypeId] = fmt.Sprintf("custom table %q", mapping.Table)
+	}
+	for _, field := range c.groupByFields {
+		id := listFieldResourceTypeId(field)
+		if owner, ok := taken[id]; ok {
+			return fmt.Errorf("bamboohr-connector: group-by field %q resource type %q conflicts with %s", field, id, owner)
+		}
+		taken[id] = fmt.Sprintf("group-by field %q", field)
+	}
+	return nil
+}
+
+// checkSensitiveFields rejects configuration that names a denied field or
+// table, so a misconfiguration fails at startup rather than mid-sync.
+func (c *BambooHr) checkSensitiveFields() error {
+	fields := c.userConfig.profileFieldNames()
+	if c.userConfig.filter != nil {
+		fields = append(fields, c.userConfig.filter.Fields()...)
+	}
+	fields = append(fields, c.groupByFields...)
+	for _, mapping := range c.customTables {
+		if client.IsDeniedTable(mapping.Table) {
+			return fmt.Errorf("bamboohr-connector: custom table %q holds sensitive data and is denied unless sensitive fields are explicitly allowed", mapping.Table)
+		}
+		fields = append(fields, mapping.KeyColumn, mapping.DisplayColumn, mapping.EmployeeColumn)
+	}
+
+	for _, field := range fields {
+		if client.IsDeniedField(field) {
+			return fmt.Errorf("bamboohr-connector: field %q is sensitive and is denied unless sensitive fields are explicitly allowed", field)
+		}
+	}
+	return nil
+}
+
 func (c *BambooHr) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	_, err := c.Validate(ctx)
 	if err != nil {
@@ -43,7 +420,7 @@ func (c *BambooHr) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error)
 
 	var annos annotations.Annotations
 	annos.Update(&v2.ExternalLink{
-		Url: c.customerDomain,
+		Url: tenantURL(c.customerDomain),
 	})
 
 	return &v2.ConnectorMetadata{
@@ -52,20 +429,120 @@ func (c *BambooHr) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error)
 	}, nil
 }
 
+// Validate checks the API key. The syncer calls it at the start of every sync,
+// so it is also where the sensitive field override is reported.
 func (c *BambooHr) Validate(ctx context.Context) (annotations.Annotations, error) {
-	_, _, err := c.client.ListUsers(ctx)
+	if c.allowSensitiveFields {
+		ctxzap.Extract(ctx).Warn(
+			"sensitive field denylist is overridden, SSNs, compensation, bank details and dates of birth may be synced",
+			zap.Strings("denied_fields", client.DeniedFields),
+			zap.Strings("denied_tables", client.DeniedTables),
+		)
+	}
+
+	// Start the sync from fresh data. Listing employees also checks the key,
+	// and the result is kept for the syncers.
+	c.directory.reset()
+	_, _, err := c.directory.listEmployees(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate API keys: %w", err)
 	}
+
+	// Group-by fields are only resolved against /meta/lists here.
+	err = c.listFieldHierarchy().validate(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
 func (c *BambooHr) Asset(ctx context.Context, asset *v2.AssetRef) (string, io.ReadCloser, error) {
-	return "", nil, nil
+	if employeeId, ok := strings.CutPrefix(asset.GetId(), employeePhotoAssetPrefix); ok {
+		return c.employeePhoto(ctx, employeeId)
+	}
+
+	switch asset.GetId() {
+	case companyLogoAssetId:
+		logo, contentType, _, err := c.client.GetCompanyLogo(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		return contentType, io.NopCloser(bytes.NewReader(logo)), nil
+	default:
+		return "", nil, fmt.Errorf("bamboohr-connector: unknown asset %q", asset.GetId())
+	}
+}
+
+// employeePhoto serves an employee photo at the configured size. Employees
+// whose photo has been removed get an empty asset rather than an error, so that
+// one missing photo does not fail the sync. Photos are fetched on the shared
+// pool, so they count towards the per-employee concurrency.
+func (c *BambooHr) employeePhoto(ctx context.Context, employeeId string) (string, io.ReadCloser, error) {
+	var (
+		photo       []byte
+		contentType string
+	)
+	_, err := c.pool.Do(ctx, func(ctx context.Context) (*v2.RateLimitDescription, error) {
+		var (
+			ratelimitData *v2.RateLimitDescription
+			err           error
+		)
+		photo, contentType, ratelimitData, err = c.client.GetEmployeePhoto(ctx, employeeId, c.photoSize)
+		return ratelimitData, err
+	})
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			ctxzap.Extract(ctx).Debug(
+				"employee has no photo",
+				zap.String("employee_id", employeeId),
+			)
+			return "", io.NopCloser(bytes.NewReader(nil)), nil
+		}
+		return "", nil, err
+	}
+	return contentType, io.NopCloser(bytes.NewReader(photo)), nil
+}
+
+// listFieldHierarchy returns a list field syncer for each group-by field,
+// sharing a hierarchy that nests departments in divisions.
+func (c *BambooHr) listFieldHierarchy() *listFieldHierarchy {
+	hierarchy := &listFieldHierarchy{directory: c.directory, parents: c.departmentDivisions}
+	for _, field := range c.groupByFields {
+		builder := listFieldBuilder(c.directory, field)
+		builder.hierarchy = hierarchy
+		hierarchy.fields = append(hierarchy.fields, builder)
+	}
+	return hierarchy
 }
 
 func (c *BambooHr) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
-		userBuilder(c.client),
+	syncers := []connectorbuilder.ResourceSyncer{
+		userBuilder(c.directory),
+		benefitPlanBuilder(c.directory),
+		assetBuilder(c.directory),
+		managerRoleBuilder(c.directory, c.seniorManagerThreshold),
+	}
+	// The Employment group is what hire, termination and rehire events
+	// grant and revoke.
+	if c.jobChangeEvents {
+		syncers = append(syncers, employmentBuilder(c.directory))
+	}
+	for _, mapping := range c.customTables {
+		syncers = append(syncers, customTableBuilder(c.directory, mapping))
+	}
+	// Division membership is expanded from department membership.
+	hierarchy := c.listFieldHierarchy()
+	for _, builder := range hierarchy.fields {
+		syncers = append(syncers, builder)
+	}
+
+	childResourceTypes := make([]string, 0)
+	for _, syncer := range syncers {
+		resourceType := syncer.ResourceType(ctx)
+		if slices.Contains(resourceType.Traits, v2.ResourceType_TRAIT_USER) ||
+			slices.Contains(resourceType.Traits, v2.ResourceType_TRAIT_GROUP) {
+			childResourceTypes = append(childResourceTypes, resourceType.Id)
+		}
 	}
+	return append(syncers, appBuilder(c.directory, c.customerDomain, childResourceTypes))
 }
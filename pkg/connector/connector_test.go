@@ -0,0 +1,109 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+// setBaseUrl points the connector's HTTP client at a fixtures server.
+func setBaseUrl(t *testing.T, connector *BambooHr, baseUrl string) {
+	bambooHRClient, ok := connector.client.(*client.BambooHRClient)
+	require.True(t, ok)
+	bambooHRClient.SetBaseUrl(baseUrl)
+}
+
+// newClientMock returns a mock that serves one employee, one benefit plan
+// and that employee's photo.
+func newClientMock() *test.ClientMock {
+	users := []*client.User{
+		{Id: "1", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Status: "Active"},
+	}
+	photo := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	return &test.ClientMock{
+		ListUsersFunc: func(_ context.Context, _ ...string) ([]*client.User, *v2.RateLimitDescription, error) {
+			return users, nil, nil
+		},
+		ListUsersPageFunc: func(_ context.Context, _ *client.ReportFilters, _ string, _ ...string) ([]*client.User, string, *v2.RateLimitDescription, error) {
+			return users, "", nil, nil
+		},
+		ListLoginUsersFunc: func(_ context.Context) ([]*client.LoginUser, *v2.RateLimitDescription, error) {
+			return nil, nil, nil
+		},
+		ListTableRowsFunc: func(_ context.Context, _ string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+			return nil, nil, nil
+		},
+		ListCompanyBenefitsFunc: func(_ context.Context) ([]*client.CompanyBenefit, *v2.RateLimitDescription, error) {
+			return []*client.CompanyBenefit{{Id: "1", BenefitName: "Medical"}}, nil, nil
+		},
+		GetEmployeePhotoFunc: func(_ context.Context, employeeId string, _ string) ([]byte, string, *v2.RateLimitDescription, error) {
+			if employeeId != "1" {
+				return nil, "", nil, fmt.Errorf("bambooHR-client: error getting employee photo %w", &client.RequestError{Status: http.StatusNotFound})
+			}
+			return photo, http.DetectContentType(photo), nil, nil
+		},
+	}
+}
+
+func TestClientMock(t *testing.T) {
+	ctx := context.Background()
+
+	mock := newClientMock()
+	connector, err := New(ctx, "mock-company", "mock-access-token", WithClient(mock))
+	require.Nil(t, err)
+
+	_, err = connector.Validate(ctx)
+	require.Nil(t, err)
+
+	t.Run("should sync from the mock", func(t *testing.T) {
+		users, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, users, 1)
+		require.Equal(t, "Ada Lovelace", users[0].DisplayName)
+
+		plans, _, _, err := benefitPlanBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, plans, 1)
+		require.Equal(t, "Medical", plans[0].DisplayName)
+		require.Len(t, mock.ListCompanyBenefitsCalls(), 1)
+	})
+
+	t.Run("should serve photos from the mock", func(t *testing.T) {
+		contentType, body, err := connector.Asset(ctx, &v2.AssetRef{Id: employeePhotoAssetId("1")})
+		require.Nil(t, err)
+		defer body.Close()
+		require.Equal(t, "image/png", contentType)
+
+		_, body, err = connector.Asset(ctx, &v2.AssetRef{Id: employeePhotoAssetId("2")})
+		require.Nil(t, err)
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		require.Nil(t, err)
+		require.Empty(t, data)
+	})
+
+	t.Run("should surface errors from the mock", func(t *testing.T) {
+		failing := newClientMock()
+		failing.ListUsersPageFunc = func(_ context.Context, _ *client.ReportFilters, _ string, _ ...string) ([]*client.User, string, *v2.RateLimitDescription, error) {
+			return nil, "", nil, errors.New("unavailable")
+		}
+		connector, err := New(ctx, "mock-company", "mock-access-token", WithClient(failing))
+		require.Nil(t, err)
+		_, err = connector.Validate(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("should reject a data source without the HTTP client", func(t *testing.T) {
+		_, err := New(ctx, "mock-company", "mock-access-token", WithClient(mock), WithDataSource(client.DataSourceDatasets))
+		require.Error(t, err)
+	})
+}
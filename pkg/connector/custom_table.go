@@ -0,0 +1,312 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	customTableTraitGroup         = "group"
+	customTableTraitRole          = "role"
+	customTableTraitApp           = "app"
+	customTableDefaultEntitlement = "member"
+	customTableDefaultEmployeeCol = "employeeId"
+)
+
+// CustomTableMapping describes how rows of a BambooHR table become resources.
+// Every distinct value of KeyColumn becomes a resource, and every employee with
+// a row holding that value is granted its entitlement.
+type CustomTableMapping struct {
+	Table          string `yaml:"table"`
+	ResourceTypeId string `yaml:"resource_type"`
+	DisplayName    string `yaml:"display_name"`
+	Trait          string `yaml:"trait"`
+	KeyColumn      string `yaml:"key_column"`
+	DisplayColumn  string `yaml:"display_column"`
+	EmployeeColumn string `yaml:"employee_column"`
+	Entitlement    string `yaml:"entitlement"`
+	// PerEmployee reads the table one employee at a time, for tables that
+	// the changed-tables endpoint does not return.
+	PerEmployee bool `yaml:"per_employee"`
+}
+
+type CustomTablesConfig struct {
+	Tables []*CustomTableMapping `yaml:"tables"`
+}
+
+// LoadCustomTablesConfig reads and validates a custom table mapping file.
+func LoadCustomTablesConfig(path string) ([]*CustomTableMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("bamboohr-connector: failed to read custom tables config: %w", err)
+	}
+
+	config := &CustomTablesConfig{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("bamboohr-connector: failed to parse custom tables config: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, mapping := range config.Tables {
+		err := mapping.validate()
+		if err != nil {
+			return nil, err
+		}
+		if seen[mapping.ResourceTypeId] {
+			return nil, fmt.Errorf("bamboohr-connector: duplicate custom table resource type %q", mapping.ResourceTypeId)
+		}
+		seen[mapping.ResourceTypeId] = true
+	}
+	return config.Tables, nil
+}
+
+// validate checks required fields and fills in defaults.
+func (m *CustomTableMapping) validate() error {
+	if m.Table == "" || m.ResourceTypeId == "" || m.KeyColumn == "" {
+		return fmt.Errorf("bamboohr-connector: custom table mappings require table, resource_type and key_column")
+	}
+	for _, builtIn := range builtInResourceTypes() {
+		if m.ResourceTypeId == builtIn.Id {
+			return fmt.Errorf("bamboohr-connector: custom table resource type %q conflicts with a built-in resource type", m.ResourceTypeId)
+		}
+	}
+
+	if m.Trait == "" {
+		m.Trait = customTableTraitGroup
+	}
+	switch strings.ToLower(m.Trait) {
+	case customTableTraitGroup, customTableTraitRole, customTableTraitApp:
+		m.Trait = strings.ToLower(m.Trait)
+	default:
+		return fmt.Errorf("bamboohr-connector: custom table %q has unsupported trait %q", m.Table, m.Trait)
+	}
+
+	if m.DisplayName == "" {
+		m.DisplayName = m.Table
+	}
+	if m.DisplayColumn == "" {
+		m.DisplayColumn = m.KeyColumn
+	}
+	if m.EmployeeColumn == "" {
+		m.EmployeeColumn = customTableDefaultEmployeeCol
+	}
+	if m.Entitlement == "" {
+		m.Entitlement = customTableDefaultEntitlement
+	}
+	return nil
+}
+
+func (m *CustomTableMapping) resourceType() *v2.ResourceType {
+	var trait v2.ResourceType_Trait
+	switch m.Trait {
+	case customTableTraitRole:
+		trait = v2.ResourceType_TRAIT_ROLE
+	case customTableTraitApp:
+		trait = v2.ResourceType_TRAIT_APP
+	default:
+		trait = v2.ResourceType_TRAIT_GROUP
+	}
+	return &v2.ResourceType{
+		Id:          m.ResourceTypeId,
+		DisplayName: m.DisplayName,
+		Traits:      []v2.ResourceType_Trait{trait},
+	}
+}
+
+type CustomTableResourceType struct {
+	resourceType   *v2.ResourceType
+	mapping        *CustomTableMapping
+	bambooHRClient client.Client
+	directory      *directory
+}
+
+func (o *CustomTableResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func (o *CustomTableResourceType) List(
+	ctx context.Context,
+	parentResourceID *v2.ResourceId,
+	_ *pagination.Token,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if listedAsAppChild(parentResourceID) {
+		return nil, "", nil, nil
+	}
+
+	rows, ratelimitData, err := o.rows(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	rv := make([]*v2.Resource, 0)
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		key := row.Get(o.mapping.KeyColumn)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		newResource, err := o.customTableResource(row)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, newResource)
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func (o *CustomTableResourceType) Entitlements(
+	_ context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Entitlement,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			o.mapping.Entitlement,
+			entitlement.WithGrantableTo(resourceTypeUser),
+			entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, o.mapping.Entitlement)),
+			entitlement.WithDescription(fmt.Sprintf("%s of %s in %s", o.mapping.Entitlement, resource.DisplayName, o.mapping.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+func (o *CustomTableResourceType) Grants(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Grant,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	if err != nil {
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+	rows, ratelimitData, err := o.rows(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	rv := make([]*v2.Grant, 0)
+	granted := make(map[string]bool)
+	for _, row := range rows {
+		if row.Get(o.mapping.KeyColumn) != resource.Id.Resource {
+			continue
+		}
+		employeeId := row.Get(o.mapping.EmployeeColumn)
+		if !employees.includes(employeeId) || granted[employeeId] {
+			continue
+		}
+		granted[employeeId] = true
+
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     employeeId,
+		}
+		rv = append(rv, grant.NewGrant(resource, o.mapping.Entitlement, principal))
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func customTableBuilder(directory *directory, mapping *CustomTableMapping) *CustomTableResourceType {
+	return &CustomTableResourceType{
+		resourceType:   mapping.resourceType(),
+		mapping:        mapping,
+		bambooHRClient: directory.bambooHRClient,
+		directory:      directory,
+	}
+}
+
+// rows reads every row of the mapped table, once per sync. Per-employee tables
+// are read for the synced employees only; see perEmployee.
+func (o *CustomTableResourceType) rows(ctx context.Context) ([]client.TableRow, *v2.RateLimitDescription, error) {
+	if !o.mapping.PerEmployee {
+		return readOnce(ctx, o.directory, "table:"+o.mapping.Table, func(ctx context.Context) ([]client.TableRow, *v2.RateLimitDescription, error) {
+			return o.bambooHRClient.ListTableRows(ctx, o.mapping.Table)
+		})
+	}
+
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	if err != nil {
+		return nil, ratelimitData, err
+	}
+	employeeRows, ratelimitData, err := perEmployee(
+		ctx,
+		o.directory,
+		"employeeTable:"+o.mapping.Table,
+		func(ctx context.Context, employeeId string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+			return o.bambooHRClient.ListEmployeeTableRows(ctx, employeeId, o.mapping.Table)
+		},
+	)
+	if err != nil {
+		return nil, ratelimitData, err
+	}
+	rv := make([]client.TableRow, 0)
+	for _, user := range employees.included {
+		rv = append(rv, employeeRows[user.Id]...)
+	}
+	return rv, ratelimitData, nil
+}
+
+// customTableResource converts a table row into a Resource with the trait
+// configured for its table.
+func (o *CustomTableResourceType) customTableResource(row client.TableRow) (*v2.Resource, error) {
+	key := row.Get(o.mapping.KeyColumn)
+	displayName := row.Get(o.mapping.DisplayColumn)
+	if displayName == "" {
+		displayName = key
+	}
+	profile := map[string]interface{}{
+		"table":             o.mapping.Table,
+		o.mapping.KeyColumn: key,
+	}
+
+	switch o.mapping.Trait {
+	case customTableTraitRole:
+		return resource.NewRoleResource(
+			displayName,
+			o.resourceType,
+			key,
+			[]resource.RoleTraitOption{resource.WithRoleProfile(profile)},
+		)
+	case customTableTraitApp:
+		return resource.NewAppResource(
+			displayName,
+			o.resourceType,
+			key,
+			[]resource.AppTraitOption{resource.WithAppProfile(profile)},
+		)
+	default:
+		return resource.NewGroupResource(
+			displayName,
+			o.resourceType,
+			key,
+			[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+		)
+	}
+}
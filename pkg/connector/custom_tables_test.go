@@ -0,0 +1,67 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomTables(t *testing.T) {
+	ctx := context.Background()
+
+	mappings, err := LoadCustomTablesConfig("../../test/fixtures/custom_tables.yaml")
+	require.Nil(t, err)
+	require.Len(t, mappings, 1)
+	require.Equal(t, "employeeId", mappings[0].EmployeeColumn)
+	require.Equal(t, "member", mappings[0].Entitlement)
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	bambooHRClient, err := client.New(
+		ctx,
+		"mock-access-token",
+		"mock-company",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bambooHRClient.SetBaseUrl(server.URL)
+	c := customTableBuilder(newDirectory(bambooHRClient, nil, userConfig{}, nil), mappings[0])
+	require.Equal(t, "system_access", c.ResourceType(ctx).Id)
+	require.Equal(t, []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE}, c.ResourceType(ctx).Traits)
+
+	resources, _, listAnnotations, err := c.List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	test.AssertNoRatelimitAnnotations(t, listAnnotations)
+	require.Len(t, resources, 2)
+
+	var netsuite *v2.Resource
+	for _, r := range resources {
+		if r.Id.Resource == "netsuite" {
+			netsuite = r
+		}
+	}
+	require.NotNil(t, netsuite)
+	require.Equal(t, "NetSuite", netsuite.DisplayName)
+
+	// Rows of "other", who is not in the employees report, are not granted.
+	grants, _, _, err := c.Grants(ctx, netsuite, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, "id", grants[0].Principal.Id.Resource)
+}
+
+func TestCustomTablesConfigRejectsBuiltInTypes(t *testing.T) {
+	mapping := &CustomTableMapping{
+		Table:          "customSystemAccess",
+		ResourceTypeId: resourceTypeUser.Id,
+		KeyColumn:      "customSystem",
+	}
+	require.Error(t, mapping.validate())
+}
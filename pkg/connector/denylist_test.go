@@ -0,0 +1,120 @@
+package connector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSensitiveFieldDenylist(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.AddEmployee(map[string]string{
+		"firstName":  "Ada",
+		"lastName":   "Lovelace",
+		"workEmail":  "ada@example.com",
+		"department": "Engineering",
+		"ssn":        "123-45-6789",
+	})
+
+	// profile lists the connector's users and returns the only user's profile.
+	profile := func(t *testing.T, connector *BambooHr) map[string]interface{} {
+		resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, resources, 1)
+		userTrait, err := resource.GetUserTrait(resources[0])
+		require.Nil(t, err)
+		return userTrait.Profile.AsMap()
+	}
+
+	t.Run("should reject denied fields and tables at startup", func(t *testing.T) {
+		_, err := New(ctx, "mock-company", "mock-access-token", WithProfileFields([]string{"department", "SSN"}))
+		require.ErrorContains(t, err, `"SSN" is sensitive`)
+
+		_, err = New(ctx, "mock-company", "mock-access-token", WithUserFilter(`payRate > "0"`))
+		require.ErrorContains(t, err, `"payRate" is sensitive`)
+
+		_, err = New(ctx, "mock-company", "mock-access-token", WithCustomTables([]*CustomTableMapping{
+			{Table: "directDeposit", ResourceTypeId: "bank_account", KeyColumn: "id", EmployeeColumn: "employeeId"},
+		}))
+		require.ErrorContains(t, err, `"directDeposit" holds sensitive data`)
+	})
+
+	t.Run("should refuse denied fields and tables in the client", func(t *testing.T) {
+		bambooHRClient := fakeServerClient(t, server, client.DataSourceCustomReport)
+
+		_, _, err := bambooHRClient.ListUsers(ctx, "dateOfBirth")
+		require.ErrorIs(t, err, client.ErrSensitiveField)
+		_, _, err = bambooHRClient.ListTableRows(ctx, "compensation")
+		require.ErrorIs(t, err, client.ErrSensitiveField)
+		require.Empty(t, server.Requests())
+	})
+
+	t.Run("should resolve field ids and names before checking", func(t *testing.T) {
+		server.AddField(&test.FakeField{Id: "5", Name: "SSN", Type: "ssn", Alias: "ssn"})
+		server.AddField(&test.FakeField{Id: "4322", Name: "Retention Bonus", Type: "currency"})
+		server.AddField(&test.FakeField{Id: "4", Name: "Department", Type: "list", Alias: "department"})
+		bambooHRClient := fakeServerClient(t, server, client.DataSourceDatasets)
+
+		for _, field := range []string{"5", "Retention Bonus", "4322"} {
+			_, _, err := bambooHRClient.ListUsers(ctx, field)
+			require.ErrorIs(t, err, client.ErrSensitiveField, field)
+		}
+		_, _, err := bambooHRClient.ListUsers(ctx, "4")
+		require.Nil(t, err)
+		fieldRequests := 0
+		for _, request := range server.Requests() {
+			if strings.HasSuffix(request, "/meta/fields") {
+				fieldRequests++
+			}
+		}
+		require.Equal(t, 1, fieldRequests)
+	})
+
+	t.Run("should copy profile fields", func(t *testing.T) {
+		connector, err := New(
+			ctx,
+			"mock-company",
+			"mock-access-token",
+			WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+			WithProfileFields([]string{"department"}),
+		)
+		require.Nil(t, err)
+
+		userProfile := profile(t, connector)
+		require.Equal(t, "Engineering", userProfile["department"])
+		require.NotContains(t, userProfile, "ssn")
+	})
+
+	t.Run("should sync sensitive fields and warn when allowed", func(t *testing.T) {
+		connector, err := New(
+			ctx,
+			"mock-company",
+			"mock-access-token",
+			WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+			WithProfileFields([]string{"ssn"}),
+			WithSensitiveFieldsAllowed(),
+		)
+		require.Nil(t, err)
+		require.Equal(t, "123-45-6789", profile(t, connector)["ssn"])
+
+		core, logs := observer.New(zapcore.WarnLevel)
+		for i := 0; i < 2; i++ {
+			_, err = connector.Validate(ctxzap.ToContext(ctx, zap.New(core)))
+			require.Nil(t, err)
+		}
+		require.Equal(t, 2, logs.FilterMessageSnippet("sensitive field denylist is overridden").Len())
+	})
+}
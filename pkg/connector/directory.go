@@ -0,0 +1,364 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// directory holds the BambooHR data that several syncers read, so that a sync
+// reads each of them once rather than once per resource or page: the list
+// fields from /meta/lists, the employees report and anything stored with
+// readOnce or perEmployee. The report requests every field a syncer needs,
+// which are the user filter, profile and group-by fields.
+//
+// The employees report is held in memory for the whole sync, however it is
+// paged. The org chart, manager roles and every syncer's check of which
+// employees are synced need all employees, and reading the report again for
+// each of them would cost far more requests. With the Datasets API, pages
+// keep each response small enough not to time out and let users be returned
+// a page at a time, but they do not lower memory use.
+//
+// BambooHr.Validate resets the directory, and the syncer calls it at the start
+// of every sync, so each sync still sees current data.
+type directory struct {
+	bambooHRClient client.Client
+	pool           *client.Pool
+	config         userConfig
+	groupByFields  []string
+
+	mu        sync.Mutex
+	lists     []*client.ListField
+	employees *employees
+	reads     map[string]*directoryRead
+}
+
+// directoryRead is a value stored with readOnce. Its own lock lets one read
+// wait for another without holding the directory's.
+type directoryRead struct {
+	mu    sync.Mutex
+	done  bool
+	value interface{}
+}
+
+// employees is the result of the employees report.
+type employees struct {
+	// all is every employee in BambooHR, including those the server-side
+	// filters left out of pages.
+	all []*client.User
+	// included is the employees the user config keeps, which are the ones
+	// synced as users, and includedIds their ids.
+	included    []*client.User
+	includedIds map[string]bool
+	// trackedIds is the ids of the employees in all that the user config
+	// keeps whatever their status, so terminated employees left out of the
+	// sync still have their terminations reported.
+	trackedIds map[string]bool
+	// org is the org chart of every employee in all.
+	org *orgChart
+	// pages is every employee the filtered report returned, as BambooHR
+	// paged them.
+	pages []*employeePage
+}
+
+// employeePage is a page of the employees report. token is the page token it
+// was read with and next the token of the following page, or "" after the
+// last.
+type employeePage struct {
+	token string
+	next  string
+	users []*client.User
+}
+
+// page returns the page read with token.
+func (e *employees) page(token string) (*employeePage, error) {
+	for _, page := range e.pages {
+		if page.token == token {
+			return page, nil
+		}
+	}
+	return nil, fmt.Errorf("bamboohr-connector: employee page %q not found", token)
+}
+
+// includes reports whether an employee is synced as a user. Grants and events
+// are only emitted for these employees, so that the user filter, terminated
+// employee and future hire options apply to every syncer.
+func (e *employees) includes(employeeId string) bool {
+	return e.includedIds[employeeId]
+}
+
+// tracks reports whether an employee's hires, terminations and rehires are
+// reported as events. These are the included employees and, whatever the
+// terminated employee options, the terminated employees the user filter
+// keeps.
+func (e *employees) tracks(employeeId string) bool {
+	return e.trackedIds[employeeId]
+}
+
+func newDirectory(bambooHRClient client.Client, pool *client.Pool, config userConfig, groupByFields []string) *directory {
+	return &directory{
+		bambooHRClient: bambooHRClient,
+		pool:           pool,
+		config:         config,
+		groupByFields:  groupByFields,
+	}
+}
+
+// reset drops everything read so far, so the next reads go to BambooHR.
+func (d *directory) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lists = nil
+	d.employees = nil
+	d.reads = nil
+}
+
+// readOnce returns the value stored under key for this sync, calling read to
+// store it the first time. Failed reads are not stored, so they are retried.
+func readOnce[T any](
+	ctx context.Context,
+	d *directory,
+	key string,
+	read func(ctx context.Context) (T, *v2.RateLimitDescription, error),
+) (T, *v2.RateLimitDescription, error) {
+	d.mu.Lock()
+	if d.reads == nil {
+		d.reads = make(map[string]*directoryRead)
+	}
+	entry, ok := d.reads[key]
+	if !ok {
+		entry = &directoryRead{}
+		d.reads[key] = entry
+	}
+	d.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.done {
+		return entry.value.(T), nil, nil
+	}
+	value, ratelimitData, err := read(ctx)
+	if err != nil {
+		return value, ratelimitData, err
+	}
+	entry.value, entry.done = value, true
+	return value, ratelimitData, nil
+}
+
+// perEmployee returns the result of fetch for every synced employee, read on
+// the shared pool once per sync and stored under key. An employee whose read
+// fails for a reason retrying cannot fix is logged and left out, rather than
+// failing the page; see client.ForEachEmployee.
+func perEmployee[T any](
+	ctx context.Context,
+	d *directory,
+	key string,
+	fetch func(ctx context.Context, employeeId string) (T, *v2.RateLimitDescription, error),
+) (map[string]T, *v2.RateLimitDescription, error) {
+	return readOnce(ctx, d, key, func(ctx context.Context) (map[string]T, *v2.RateLimitDescription, error) {
+		employees, ratelimitData, err := d.listEmployees(ctx)
+		if err != nil {
+			return nil, ratelimitData, err
+		}
+		employeeIds := make([]string, 0, len(employees.included))
+		for _, user := range employees.included {
+			employeeIds = append(employeeIds, user.Id)
+		}
+
+		results, err := client.ForEachEmployee(ctx, d.pool, employeeIds, fetch)
+		if results.RateLimit != nil {
+			ratelimitData = results.RateLimit
+		}
+		if err != nil {
+			return nil, ratelimitData, err
+		}
+		for _, employeeId := range employeeIds {
+			if err, ok := results.Errors[employeeId]; ok {
+				ctxzap.Extract(ctx).Warn(
+					"failed to read employee data, skipping employee",
+					zap.String("read", key),
+					zap.String("employee_id", employeeId),
+					zap.Error(err),
+				)
+			}
+		}
+		return results.Values, ratelimitData, nil
+	})
+}
+
+// listFields returns every list field from /meta/lists.
+func (d *directory) listFields(ctx context.Context) ([]*client.ListField, *v2.RateLimitDescription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.listFieldsLocked(ctx)
+}
+
+func (d *directory) listFieldsLocked(ctx context.Context) ([]*client.ListField, *v2.RateLimitDescription, error) {
+	if d.lists != nil {
+		return d.lists, nil, nil
+	}
+	lists, ratelimitData, err := d.bambooHRClient.ListListFields(ctx)
+	if err != nil {
+		return nil, ratelimitData, err
+	}
+	d.lists = lists
+	return lists, ratelimitData, nil
+}
+
+// listField finds a list field by alias, name or id.
+func (d *directory) listField(ctx context.Context, field string) (*client.ListField, *v2.RateLimitDescription, error) {
+	lists, ratelimitData, err := d.listFields(ctx)
+	if err != nil {
+		return nil, ratelimitData, err
+	}
+	list := findListField(lists, field)
+	if list == nil {
+		return nil, ratelimitData, fmt.Errorf("bamboohr-connector: list field %q not found", field)
+	}
+	return list, ratelimitData, nil
+}
+
+func findListField(lists []*client.ListField, field string) *client.ListField {
+	for _, list := range lists {
+		if strings.EqualFold(list.Alias, field) ||
+			strings.EqualFold(list.Name, field) ||
+			list.FieldId.String() == field {
+			return list
+		}
+	}
+	return nil
+}
+
+// loginUsers returns the BambooHR login accounts. Reading them needs more
+// access than reading employees, so an API key without it gets no accounts,
+// and so no last logins or login_access grants, rather than a failed sync.
+func (d *directory) loginUsers(ctx context.Context) ([]*client.LoginUser, *v2.RateLimitDescription, error) {
+	return readOnce(ctx, d, "loginUsers", func(ctx context.Context) ([]*client.LoginUser, *v2.RateLimitDescription, error) {
+		loginUsers, ratelimitData, err := d.bambooHRClient.ListLoginUsers(ctx)
+		if errors.Is(err, client.ErrPermissionDenied) {
+			ctxzap.Extract(ctx).Warn(
+				"api key cannot list BambooHR login accounts, skipping last logins and login access",
+				zap.Error(err),
+			)
+			return nil, ratelimitData, nil
+		}
+		return loginUsers, ratelimitData, err
+	})
+}
+
+// listEmployees runs the employees report with the server-side filters the
+// user filter and the terminated employee options need, and splits out the
+// employees the user config keeps. When filters are sent, the report is run
+// again without them, so that the org chart still sees supervisors the
+// filters leave out. Group-by fields that are not in /meta/lists are left out
+// of the report; their syncers fail on their own.
+func (d *directory) listEmployees(ctx context.Context) (*employees, *v2.RateLimitDescription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.employees != nil {
+		return d.employees, nil, nil
+	}
+
+	var filters *client.ReportFilters
+	extraFields := d.config.profileFieldNames()
+	if d.config.filter != nil {
+		filters = d.config.filter.ReportFilters()
+		extraFields = append(extraFields, d.config.filter.Fields()...)
+	}
+	if d.config.excludeTerminated {
+		if filters == nil {
+			filters = &client.ReportFilters{}
+		}
+		filters.Status = client.UserStatusActive
+	}
+	if len(d.groupByFields) > 0 {
+		lists, ratelimitData, err := d.listFieldsLocked(ctx)
+		if err != nil {
+			return nil, ratelimitData, err
+		}
+		for _, field := range d.groupByFields {
+			if list := findListField(lists, field); list != nil {
+				extraFields = append(extraFields, list.ReportField())
+			}
+		}
+	}
+
+	pages, ratelimitData, err := d.readEmployeePages(ctx, filters, extraFields)
+	if err != nil {
+		return nil, ratelimitData, err
+	}
+	users := make([]*client.User, 0)
+	for _, page := range pages {
+		users = append(users, page.users...)
+	}
+	all := users
+	if filters != nil {
+		var unfiltered []*employeePage
+		unfiltered, ratelimitData, err = d.readEmployeePages(ctx, nil, extraFields)
+		if err != nil {
+			return nil, ratelimitData, err
+		}
+		all = make([]*client.User, 0, len(users))
+		for _, page := range unfiltered {
+			all = append(all, page.users...)
+		}
+	}
+
+	now := time.Now()
+	rv := &employees{
+		all:         all,
+		included:    make([]*client.User, 0, len(users)),
+		includedIds: make(map[string]bool, len(users)),
+		trackedIds:  make(map[string]bool, len(all)),
+		org:         newOrgChart(all),
+		pages:       pages,
+	}
+	for _, user := range users {
+		if d.config.includes(user, now) {
+			rv.included = append(rv.included, user)
+			rv.includedIds[user.Id] = true
+		}
+	}
+	anyStatus := d.config
+	anyStatus.excludeTerminated, anyStatus.terminatedRetentionDays = false, 0
+	for _, user := range all {
+		if rv.includedIds[user.Id] || anyStatus.includes(user, now) {
+			rv.trackedIds[user.Id] = true
+		}
+	}
+	d.employees = rv
+	return d.employees, ratelimitData, nil
+}
+
+// readEmployeePages reads every page of the employees report.
+func (d *directory) readEmployeePages(
+	ctx context.Context,
+	filters *client.ReportFilters,
+	extraFields []string,
+) ([]*employeePage, *v2.RateLimitDescription, error) {
+	var (
+		pages         []*employeePage
+		ratelimitData *v2.RateLimitDescription
+	)
+	for token := ""; ; {
+		page := &employeePage{token: token}
+		var err error
+		page.users, page.next, ratelimitData, err = d.bambooHRClient.ListUsersPage(ctx, filters, token, extraFields...)
+		if err != nil {
+			return nil, ratelimitData, err
+		}
+		pages = append(pages, page)
+		if page.next == "" {
+			return pages, ratelimitData, nil
+		}
+		token = page.next
+	}
+}
@@ -0,0 +1,117 @@
+package connector
+
+import (
+	"context"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const (
+	employedResourceId          = "employed"
+	employmentMemberEntitlement = "member"
+)
+
+// EmploymentResourceType syncs a single Employed group, granted to the synced
+// employees that are currently employed. Job change events grant it on hires
+// and rehires and revoke it on terminations, so the event feed records who
+// was employed at any past date.
+type EmploymentResourceType struct {
+	resourceType *v2.ResourceType
+	directory    *directory
+}
+
+func (o *EmploymentResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func (o *EmploymentResourceType) List(
+	_ context.Context,
+	parentResourceID *v2.ResourceId,
+	_ *pagination.Token,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if listedAsAppChild(parentResourceID) {
+		return nil, "", nil, nil
+	}
+
+	employed, err := employedResource()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return []*v2.Resource{employed}, "", nil, nil
+}
+
+func (o *EmploymentResourceType) Entitlements(
+	_ context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Entitlement,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			employmentMemberEntitlement,
+			entitlement.WithGrantableTo(resourceTypeUser),
+			entitlement.WithDisplayName(resource.DisplayName),
+			entitlement.WithDescription("Is currently employed"),
+		),
+	}, "", nil, nil
+}
+
+// Grants grants the group to the synced employees that are active and whose
+// hire date has arrived.
+func (o *EmploymentResourceType) Grants(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Grant,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	now := time.Now()
+	rv := make([]*v2.Grant, 0)
+	for _, user := range employees.included {
+		if !user.IsActive() || isFutureHire(user, now) {
+			continue
+		}
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     user.Id,
+		}
+		rv = append(rv, grant.NewGrant(resource, employmentMemberEntitlement, principal))
+	}
+	return rv, "", outputAnnotations, nil
+}
+
+func employedResource() (*v2.Resource, error) {
+	return resource.NewGroupResource(
+		"Employed",
+		resourceTypeEmployment,
+		employedResourceId,
+		nil,
+	)
+}
+
+func employmentBuilder(directory *directory) *EmploymentResourceType {
+	return &EmploymentResourceType{
+		resourceType: resourceTypeEmployment,
+		directory:    directory,
+	}
+}
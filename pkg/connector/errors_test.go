@@ -0,0 +1,75 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequestErrors(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+
+	for _, tc := range []struct {
+		status   int
+		sentinel error
+		code     codes.Code
+	}{
+		{http.StatusBadRequest, client.ErrBadRequest, codes.InvalidArgument},
+		{http.StatusUnauthorized, client.ErrUnauthenticated, codes.Unauthenticated},
+		{http.StatusForbidden, client.ErrPermissionDenied, codes.PermissionDenied},
+		{http.StatusNotFound, client.ErrNotFound, codes.NotFound},
+		{http.StatusInternalServerError, client.ErrUnavailable, codes.Unavailable},
+		{http.StatusConflict, client.ErrUnexpectedStatus, codes.Unknown},
+	} {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			server.InjectFailure(test.Failure{
+				Path:    client.CompanyBenefitsUrlPath,
+				Status:  tc.status,
+				Message: "Something went wrong",
+				Times:   1,
+			})
+
+			_, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListCompanyBenefits(ctx)
+			require.ErrorIs(t, err, tc.sentinel)
+			require.Equal(t, tc.code, status.Code(err))
+			require.Contains(t, err.Error(), "Something went wrong")
+		})
+	}
+
+	t.Run("should keep the body out of the message", func(t *testing.T) {
+		server.InjectFailure(test.Failure{
+			Path:   client.CompanyBenefitsUrlPath,
+			Status: http.StatusConflict,
+			Body:   `{"employee":"Ada Lovelace"}`,
+			Times:  1,
+		})
+
+		_, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListCompanyBenefits(ctx)
+		var requestError *client.RequestError
+		require.True(t, errors.As(err, &requestError))
+		require.Contains(t, requestError.Body, "Ada Lovelace")
+		require.NotContains(t, err.Error(), "Ada Lovelace")
+		require.Contains(t, err.Error(), http.StatusText(http.StatusConflict))
+	})
+
+	t.Run("should treat network errors as retryable", func(t *testing.T) {
+		bambooHRClient := fakeServerClient(t, server, client.DataSourceCustomReport)
+		server.Close()
+
+		_, _, err := bambooHRClient.ListCompanyBenefits(ctx)
+		var networkError *client.NetworkError
+		require.True(t, errors.As(err, &networkError))
+		require.ErrorIs(t, err, client.ErrUnavailable)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
@@ -0,0 +1,229 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// jobChangeEventFields are the jobInfo fields whose changes become events,
+// when they are also synced as groups with WithGroupByFields. They are also
+// the aliases of the list fields that hold them.
+var jobChangeEventFields = []string{departmentField, divisionField}
+
+// eventGroups is a synced jobInfo field's resource type and option ids by name.
+type eventGroups struct {
+	resourceTypeId string
+	options        map[string]string
+}
+
+// ListEvents reports jobInfo and employmentStatus changes that took effect
+// between earliestEvent and now, when enabled with WithJobChangeEvents. A
+// hire or rehire grants the Employment group and a termination revokes it. A
+// change of department or division becomes a revoke event for the old group
+// and a grant event for the new one, so access can follow a transfer on the
+// day it happens. A termination also revokes the employee's groups and a
+// rehire grants them again, so the events answer whether an employee was
+// employed, and in which groups, at any past date. Employment events are
+// reported for terminated employees even when they are not synced, so their
+// terminations are seen; group events only for synced employees. All events
+// are returned in one page.
+//
+// The cursor is the date of the last poll. A poll with a cursor only reports
+// changes from that date on, so history is not replayed. Changes dated on
+// the cursor day are reported again, under the same ids, so that rows added
+// later that day are not missed.
+func (c *BambooHr) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	now := time.Now()
+	var since time.Time
+	if earliestEvent != nil {
+		since = earliestEvent.AsTime()
+	}
+	if pToken != nil && pToken.Cursor != "" {
+		cursor, ok := client.ParseDate(pToken.Cursor)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("bamboohr-connector: invalid event cursor %q", pToken.Cursor)
+		}
+		if cursor.After(since) {
+			since = cursor
+		}
+	}
+	streamState := &pagination.StreamState{Cursor: now.UTC().Format(client.DateLayout)}
+	if !c.jobChangeEvents {
+		return nil, streamState, nil, nil
+	}
+
+	// groups maps each synced event field to its groups. Group-by fields can
+	// name the list field by alias, name or id, so they are matched on the
+	// list field's alias.
+	groups := make(map[string]*eventGroups)
+	lists, ratelimitData, err := c.directory.listFields(ctx)
+	if err != nil {
+		return nil, nil, WithRateLimitAnnotations(ratelimitData), err
+	}
+	for _, field := range c.groupByFields {
+		list := findListField(lists, field)
+		if list == nil || !slices.Contains(jobChangeEventFields, list.Alias) {
+			continue
+		}
+		options := make(map[string]string, len(list.Options))
+		for _, option := range list.Options {
+			options[option.Name] = option.Id.String()
+		}
+		groups[list.Alias] = &eventGroups{resourceTypeId: listFieldResourceTypeId(field), options: options}
+	}
+
+	employees, ratelimitData, err := c.directory.listEmployees(ctx)
+	if err != nil {
+		return nil, nil, WithRateLimitAnnotations(ratelimitData), err
+	}
+	history, ratelimitData, err := listJobHistory(ctx, c.client)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, nil, outputAnnotations, err
+	}
+
+	rv := make([]*v2.Event, 0)
+	employeeIds := make(map[string]bool)
+	for employeeId := range history.jobInfo {
+		employeeIds[employeeId] = true
+	}
+	for employeeId := range history.employmentStatus {
+		employeeIds[employeeId] = true
+	}
+	for employeeId := range employeeIds {
+		// Terminated employees may be left out of the sync, but their
+		// terminations are still reported; their groups are not.
+		if !employees.tracks(employeeId) {
+			continue
+		}
+		included := employees.includes(employeeId)
+		principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: employeeId}}
+
+		// Replay the employee's history, tracking the groups they were a
+		// member of: their jobInfo values while employed, none while
+		// terminated. Employees without employmentStatus rows are treated
+		// as employed for their groups, but are never hired.
+		employed := true
+		values := make(map[string]string)
+		members := make(map[string]string)
+		hired, statusEmployed := false, false
+		for _, entry := range history.timeline(employeeId) {
+			table := jobInfoTable
+			for _, row := range entry.jobInfo {
+				for _, field := range jobChangeEventFields {
+					values[field] = row.Get(field)
+				}
+			}
+			wasEmployed := statusEmployed
+			for _, row := range entry.employmentStatus {
+				// Attribute the date's events to employmentStatus when a
+				// row starts or ends employment.
+				if employed == isTerminated(row) {
+					table = employmentStatusTable
+				}
+				employed = !isTerminated(row)
+				statusEmployed = employed
+			}
+
+			date, _ := client.ParseDate(entry.date)
+			inWindow := !date.After(now) && !date.Before(since)
+			if wasEmployed != statusEmployed {
+				if inWindow {
+					rv = append(rv, employmentEvent(employeeId, entry.date, date, statusEmployed, hired))
+				}
+				hired = hired || statusEmployed
+			}
+			for _, field := range jobChangeEventFields {
+				current := ""
+				if employed {
+					current = values[field]
+				}
+				previous := members[field]
+				members[field] = current
+				fieldGroups, ok := groups[field]
+				if !ok || !inWindow || !included || previous == current {
+					continue
+				}
+
+				id := fmt.Sprintf("%s:%s:%s:%s", table, employeeId, entry.date, field)
+				if optionId, ok := fieldGroups.options[previous]; ok {
+					group := &v2.Resource{Id: &v2.ResourceId{ResourceType: fieldGroups.resourceTypeId, Resource: optionId}, DisplayName: previous}
+					rv = append(rv, &v2.Event{
+						Id:         id + ":revoke",
+						OccurredAt: timestamppb.New(date),
+						Event: &v2.Event_RevokeEvent{RevokeEvent: &v2.RevokeEvent{
+							Entitlement: entitlement.NewAssignmentEntitlement(group, listFieldMemberEntitlement),
+							Principal:   principal,
+						}},
+					})
+				}
+				if optionId, ok := fieldGroups.options[current]; ok {
+					group := &v2.Resource{Id: &v2.ResourceId{ResourceType: fieldGroups.resourceTypeId, Resource: optionId}, DisplayName: current}
+					rv = append(rv, &v2.Event{
+						Id:         id + ":grant",
+						OccurredAt: timestamppb.New(date),
+						Event: &v2.Event_GrantEvent{GrantEvent: &v2.GrantEvent{
+							Grant: grant.NewGrant(group, listFieldMemberEntitlement, principal.Id),
+						}},
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(rv, func(i, j int) bool {
+		if !rv[i].OccurredAt.AsTime().Equal(rv[j].OccurredAt.AsTime()) {
+			return rv[i].OccurredAt.AsTime().Before(rv[j].OccurredAt.AsTime())
+		}
+		return rv[i].Id < rv[j].Id
+	})
+	return rv, streamState, outputAnnotations, nil
+}
+
+// employmentEvent returns the event of an employee's employment starting or
+// ending on a date: a grant of the Employment group for a hire, or a rehire
+// when they were hired before, and a revoke for a termination.
+func employmentEvent(employeeId string, day string, date time.Time, employed bool, hired bool) *v2.Event {
+	employment := &v2.Resource{
+		Id:          &v2.ResourceId{ResourceType: resourceTypeEmployment.Id, Resource: employedResourceId},
+		DisplayName: "Employed",
+	}
+	principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: employeeId}}
+	id := fmt.Sprintf("%s:%s:%s:%s", employmentStatusTable, employeeId, day, resourceTypeEmployment.Id)
+	if !employed {
+		return &v2.Event{
+			Id:         id + ":termination",
+			OccurredAt: timestamppb.New(date),
+			Event: &v2.Event_RevokeEvent{RevokeEvent: &v2.RevokeEvent{
+				Entitlement: entitlement.NewAssignmentEntitlement(employment, employmentMemberEntitlement),
+				Principal:   principal,
+			}},
+		}
+	}
+	kind := "hire"
+	if hired {
+		kind = "rehire"
+	}
+	return &v2.Event{
+		Id:         id + ":" + kind,
+		OccurredAt: timestamppb.New(date),
+		Event: &v2.Event_GrantEvent{GrantEvent: &v2.GrantEvent{
+			Grant: grant.NewGrant(employment, employmentMemberEntitlement, principal.Id),
+		}},
+	}
+}
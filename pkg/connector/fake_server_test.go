@@ -0,0 +1,245 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServerClient returns a BambooHR client pointed at a fake server. Each
+// call returns a new client, so reads are not served from an earlier client's
+// response cache.
+func fakeServerClient(t *testing.T, server *test.FakeServer, dataSource string) *client.BambooHRClient {
+	bambooHRClient, err := client.New(context.Background(), "mock-access-token", "mock-company")
+	require.Nil(t, err)
+	require.Nil(t, bambooHRClient.SetDataSource(dataSource))
+	bambooHRClient.SetBaseUrl(server.URL)
+	return bambooHRClient
+}
+
+// callFakeServer sends a JSON request to a fake server API path.
+func callFakeServer(t *testing.T, server *test.FakeServer, method string, path string, body interface{}) *http.Response {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		require.Nil(t, err)
+	}
+	request, err := http.NewRequest(method, server.URL+"/api/gateway.php/mock-company/v1/"+path, bytes.NewReader(data))
+	require.Nil(t, err)
+	request.SetBasicAuth("mock-access-token", client.BambooPasswordPlaceholder)
+	request.Header.Set("Accept", client.JSONContentType)
+	response, err := http.DefaultClient.Do(request)
+	require.Nil(t, err)
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
+func TestFakeServerWrites(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.APIKey = "mock-access-token"
+	server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace", "workEmail": "ada@example.com"})
+
+	listUsers := func() map[string]string {
+		resources, _, _, err := userBuilder(
+			newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{}, nil),
+		).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+
+		rv := make(map[string]string)
+		for _, r := range resources {
+			rv[r.Id.Resource] = r.DisplayName
+		}
+		return rv
+	}
+	require.Equal(t, map[string]string{"1": "Ada Lovelace"}, listUsers())
+
+	t.Run("should create and update employees", func(t *testing.T) {
+		response := callFakeServer(t, server, http.MethodPost, "employees/", map[string]string{
+			"firstName": "Grace",
+			"lastName":  "Hopper",
+		})
+		require.Equal(t, http.StatusCreated, response.StatusCode)
+		require.True(t, strings.HasSuffix(response.Header.Get("Location"), "/employees/2"))
+
+		response = callFakeServer(t, server, http.MethodPost, "employees/1", map[string]string{"lastName": "King"})
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, map[string]string{"1": "Ada King", "2": "Grace Hopper"}, listUsers())
+
+		response = callFakeServer(t, server, http.MethodPost, "employees/3", map[string]string{"lastName": "Nobody"})
+		require.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	t.Run("should add table rows", func(t *testing.T) {
+		response := callFakeServer(t, server, http.MethodPost, "employees/2/tables/assets", map[string]string{
+			"assetCategory":     "Laptop",
+			"assetSerialNumber": "SN-1",
+			"assetDateAssigned": "2024-01-01",
+		})
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		rows, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListTableRows(ctx, "assets")
+		require.Nil(t, err)
+		require.Len(t, rows, 1)
+		require.Equal(t, "2", rows[0].EmployeeId())
+		require.Equal(t, "SN-1", rows[0].Get("assetSerialNumber"))
+	})
+
+	t.Run("should add list options", func(t *testing.T) {
+		server.AddListField(&client.ListField{FieldId: "4321", Name: "Department", Options: []*client.ListOption{}})
+		response := callFakeServer(t, server, http.MethodPut, "meta/lists/4321", map[string]interface{}{
+			"options": []map[string]string{{"value": "Engineering"}},
+		})
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		lists, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListListFields(ctx)
+		require.Nil(t, err)
+		require.Len(t, lists, 1)
+		require.Len(t, lists[0].Options, 1)
+		require.Equal(t, "Engineering", lists[0].Options[0].Name)
+	})
+
+	t.Run("should register and delete webhooks", func(t *testing.T) {
+		response := callFakeServer(t, server, http.MethodPost, "webhooks/", map[string]interface{}{
+			"name":          "changes",
+			"url":           "https://example.com/hook",
+			"monitorFields": []string{"status"},
+		})
+		require.Equal(t, http.StatusCreated, response.StatusCode)
+		require.Len(t, server.Webhooks(), 1)
+
+		response = callFakeServer(t, server, http.MethodDelete, "webhooks/1", nil)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Empty(t, server.Webhooks())
+	})
+
+	t.Run("should request and approve time off", func(t *testing.T) {
+		response := callFakeServer(t, server, http.MethodPut, "employees/1/time_off/request", map[string]string{
+			"start":         "2024-03-01",
+			"end":           "2024-03-05",
+			"timeOffTypeId": "78",
+			"amount":        "5",
+		})
+		require.Equal(t, http.StatusCreated, response.StatusCode)
+
+		response = callFakeServer(t, server, http.MethodPut, "time_off/requests/1/status", map[string]string{"status": "approved"})
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		response = callFakeServer(t, server, http.MethodGet, "time_off/requests/?start=2024-03-04&end=2024-03-31&status=approved", nil)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		requests := make([]*test.FakeTimeOffRequest, 0)
+		require.Nil(t, json.NewDecoder(response.Body).Decode(&requests))
+		require.Len(t, requests, 1)
+		require.Equal(t, "1", requests[0].EmployeeId)
+	})
+
+	t.Run("should report changed employees", func(t *testing.T) {
+		since := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		response := callFakeServer(t, server, http.MethodGet, "employees/changed?since="+since, nil)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		changed := struct {
+			Employees map[string]struct {
+				Action string `json:"action"`
+			} `json:"employees"`
+		}{}
+		require.Nil(t, json.NewDecoder(response.Body).Decode(&changed))
+		require.Len(t, changed.Employees, 2)
+		require.Equal(t, "Inserted", changed.Employees["2"].Action)
+	})
+}
+
+func TestFakeServerPagination(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	for i := 0; i < client.DatasetPageSize+1; i++ {
+		server.AddEmployee(map[string]string{"firstName": "Employee", "lastName": strconv.Itoa(i)})
+	}
+
+	users, _, err := fakeServerClient(t, server, client.DataSourceDatasets).ListUsers(ctx)
+	require.Nil(t, err)
+	require.Len(t, users, client.DatasetPageSize+1)
+	require.Equal(t, "1", users[0].Id)
+	require.Equal(t, strconv.Itoa(client.DatasetPageSize+1), users[client.DatasetPageSize].Id)
+
+	datasetRequests := 0
+	for _, request := range server.Requests() {
+		if strings.Contains(request, client.DatasetsEmployeeUrlPath) {
+			datasetRequests++
+		}
+	}
+	require.Equal(t, 2, datasetRequests)
+}
+
+func TestFakeServerFailures(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace"})
+
+	t.Run("should surface rate limiting as unavailable", func(t *testing.T) {
+		server.InjectFailure(test.Failure{
+			Path:       client.UsersListUrlPath,
+			Status:     http.StatusServiceUnavailable,
+			RetryAfter: "30",
+			Times:      1,
+		})
+		_, ratelimitData, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListUsers(ctx)
+		require.Equal(t, codes.Unavailable, status.Code(errors.Unwrap(err)))
+		require.NotNil(t, ratelimitData)
+		require.NotNil(t, ratelimitData.ResetAt)
+
+		users, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListUsers(ctx)
+		require.Nil(t, err)
+		require.Len(t, users, 1)
+	})
+
+	t.Run("should reject bad credentials", func(t *testing.T) {
+		server.APIKey = "another-key"
+		defer func() { server.APIKey = "" }()
+
+		_, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListUsers(ctx)
+		var requestError *client.RequestError
+		require.ErrorAs(t, err, &requestError)
+		require.Equal(t, http.StatusUnauthorized, requestError.Status)
+	})
+
+	t.Run("should reject requests that do not ask for JSON", func(t *testing.T) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/gateway.php/mock-company/v1/"+client.MetaUsersUrlPath, nil)
+		require.Nil(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.Nil(t, err)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusNotAcceptable, response.StatusCode)
+
+		_, _, err = fakeServerClient(t, server, client.DataSourceCustomReport).ListLoginUsers(ctx)
+		require.Nil(t, err)
+	})
+
+	t.Run("should be abandoned when slow", func(t *testing.T) {
+		server.InjectFailure(test.Failure{Path: client.UsersListUrlPath, Delay: time.Second, Times: 1})
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListUsers(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
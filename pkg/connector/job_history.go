@@ -0,0 +1,219 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	jobInfoTable          = "jobInfo"
+	employmentStatusTable = "employmentStatus"
+	// employmentStatusTerminated is the employmentStatus of a row that ends
+	// employment. A later row with any other status is a rehire.
+	employmentStatusTerminated = "Terminated"
+)
+
+// pendingJobFields are the jobInfo fields copied into the profile, as
+// pending<Field>, when a future-dated row changes them.
+var pendingJobFields = map[string]string{
+	"department": "pendingDepartment",
+	"division":   "pendingDivision",
+	"jobTitle":   "pendingJobTitle",
+	"location":   "pendingLocation",
+}
+
+// jobHistory holds every employee's effective-dated jobInfo and
+// employmentStatus rows, oldest first. BambooHR keeps future-dated rows too,
+// such as a transfer scheduled for next month.
+type jobHistory struct {
+	jobInfo          map[string][]client.TableRow
+	employmentStatus map[string][]client.TableRow
+}
+
+// listJobHistory reads the jobInfo and employmentStatus tables. Reading them
+// needs more access than reading employees, so an API key without it gets an
+// empty history rather than a failed sync.
+func listJobHistory(ctx context.Context, bambooHRClient client.Client) (*jobHistory, *v2.RateLimitDescription, error) {
+	rv := &jobHistory{}
+	var ratelimitData *v2.RateLimitDescription
+	for _, table := range []string{jobInfoTable, employmentStatusTable} {
+		var (
+			rows []client.TableRow
+			err  error
+		)
+		rows, ratelimitData, err = bambooHRClient.ListTableRows(ctx, table)
+		if err != nil {
+			if !errors.Is(err, client.ErrPermissionDenied) {
+				return nil, ratelimitData, err
+			}
+			ctxzap.Extract(ctx).Warn(
+				"api key cannot read BambooHR table, skipping effective-dated changes",
+				zap.String("table", table),
+				zap.Error(err),
+			)
+		}
+
+		byEmployee := effectiveDatedRows(rows)
+		if table == jobInfoTable {
+			rv.jobInfo = byEmployee
+		} else {
+			rv.employmentStatus = byEmployee
+		}
+	}
+	return rv, ratelimitData, nil
+}
+
+// effectiveDatedRows groups rows by employee, ordered by effective date. Rows
+// without a valid date are dropped.
+func effectiveDatedRows(rows []client.TableRow) map[string][]client.TableRow {
+	rv := make(map[string][]client.TableRow)
+	for _, row := range rows {
+		if _, ok := row.EffectiveDate(); !ok {
+			continue
+		}
+		rv[row.EmployeeId()] = append(rv[row.EmployeeId()], row)
+	}
+	for _, employeeRows := range rv {
+		sort.SliceStable(employeeRows, func(i, j int) bool {
+			return employeeRows[i].Get("date") < employeeRows[j].Get("date")
+		})
+	}
+	return rv
+}
+
+// nextChange returns the first row that takes effect after now.
+func nextChange(rows []client.TableRow, now time.Time) (client.TableRow, bool) {
+	for _, row := range rows {
+		if date, _ := row.EffectiveDate(); date.After(now) {
+			return row, true
+		}
+	}
+	return nil, false
+}
+
+// pending returns the profile attributes of an employee's next jobInfo and
+// employmentStatus changes, if they have any.
+func (h *jobHistory) pending(employeeId string, now time.Time) map[string]interface{} {
+	rv := make(map[string]interface{})
+	if h == nil {
+		return rv
+	}
+
+	if row, ok := nextChange(h.jobInfo[employeeId], now); ok {
+		rv["pendingEffectiveDate"] = row.Get("date")
+		for field, attribute := range pendingJobFields {
+			if value := row.Get(field); value != "" {
+				rv[attribute] = value
+			}
+		}
+	}
+	if row, ok := nextChange(h.employmentStatus[employeeId], now); ok {
+		rv["pendingEmploymentStatus"] = row.Get("employmentStatus")
+		rv["pendingEmploymentStatusEffectiveDate"] = row.Get("date")
+	}
+	return rv
+}
+
+// historyDate is the jobInfo and employmentStatus rows of one employee that
+// take effect on the same date.
+type historyDate struct {
+	date             string
+	jobInfo          []client.TableRow
+	employmentStatus []client.TableRow
+}
+
+// timeline merges an employee's jobInfo and employmentStatus rows by date,
+// oldest first.
+func (h *jobHistory) timeline(employeeId string) []*historyDate {
+	rv := make([]*historyDate, 0)
+	if h == nil {
+		return rv
+	}
+	byDate := make(map[string]*historyDate)
+	entry := func(date string) *historyDate {
+		if byDate[date] == nil {
+			byDate[date] = &historyDate{date: date}
+			rv = append(rv, byDate[date])
+		}
+		return byDate[date]
+	}
+	for _, row := range h.jobInfo[employeeId] {
+		date := entry(row.Get("date"))
+		date.jobInfo = append(date.jobInfo, row)
+	}
+	for _, row := range h.employmentStatus[employeeId] {
+		date := entry(row.Get("date"))
+		date.employmentStatus = append(date.employmentStatus, row)
+	}
+	sort.SliceStable(rv, func(i, j int) bool {
+		return rv[i].date < rv[j].date
+	})
+	return rv
+}
+
+func isTerminated(row client.TableRow) bool {
+	return strings.EqualFold(row.Get("employmentStatus"), employmentStatusTerminated)
+}
+
+// hireDates returns the dates an employee was hired on: their first
+// employmentStatus row, and every row that follows a termination. More than
+// one hire date means the employee was rehired.
+func (h *jobHistory) hireDates(employeeId string) []string {
+	rv := make([]string, 0)
+	if h == nil {
+		return rv
+	}
+	employed := false
+	for _, row := range h.employmentStatus[employeeId] {
+		if isTerminated(row) {
+			employed = false
+			continue
+		}
+		if !employed {
+			rv = append(rv, row.Get("date"))
+			employed = true
+		}
+	}
+	return rv
+}
+
+// employmentHistory returns every jobInfo and employmentStatus row of an
+// employee, oldest first, for the profile. Row ids and denied fields are left
+// out.
+func (h *jobHistory) employmentHistory(employeeId string) []interface{} {
+	rv := make([]interface{}, 0)
+	for _, date := range h.timeline(employeeId) {
+		for _, table := range []struct {
+			name string
+			rows []client.TableRow
+		}{
+			{jobInfoTable, date.jobInfo},
+			{employmentStatusTable, date.employmentStatus},
+		} {
+			for _, row := range table.rows {
+				entry := map[string]interface{}{
+					"table":         table.name,
+					"effectiveDate": date.date,
+				}
+				for field := range row {
+					switch {
+					case field == "id" || field == "employeeId" || field == "date":
+					case client.IsDeniedField(field):
+					case row.Get(field) != "":
+						entry[field] = row.Get(field)
+					}
+				}
+				rv = append(rv, entry)
+			}
+		}
+	}
+	return rv
+}
@@ -0,0 +1,343 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestPendingJobChanges(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	connector, err := New(ctx, "mock-company", "mock-access-token")
+	require.Nil(t, err)
+	setBaseUrl(t, connector, server.URL)
+
+	resources, _, _, err := userBuilder(newDirectory(connector.client, nil, userConfig{pendingJobChanges: true}, nil)).List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, resources, 1)
+	userTrait, err := resource.GetUserTrait(resources[0])
+	require.Nil(t, err)
+
+	profile := userTrait.Profile.AsMap()
+	require.Equal(t, "2099-01-01", profile["pendingEffectiveDate"])
+	require.Equal(t, "Research", profile["pendingDepartment"])
+	require.Equal(t, "Research Engineer", profile["pendingJobTitle"])
+	require.Equal(t, "Part-Time", profile["pendingEmploymentStatus"])
+	require.Equal(t, "2099-01-01", profile["pendingEmploymentStatusEffectiveDate"])
+}
+
+func TestJobHistoryPermissionDenied(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace"})
+	server.InjectFailure(test.Failure{Path: client.ChangedTablesUrlPath, Status: http.StatusForbidden})
+
+	builder := userBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{pendingJobChanges: true}, nil))
+	resources, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, resources, 1)
+}
+
+func TestJobChangeEvents(t *testing.T) {
+	ctx := context.Background()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	date := func(days int) string {
+		return today.AddDate(0, 0, days).Format(client.DateLayout)
+	}
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.AddListField(&client.ListField{
+		FieldId: "4",
+		Alias:   "department",
+		Name:    "Department",
+		Options: []*client.ListOption{
+			{Id: "1", Name: "Engineering", Archived: "no"},
+			{Id: "2", Name: "Research", Archived: "no"},
+			{Id: "3", Name: "Sales", Archived: "no"},
+		},
+	})
+	ada := server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace", "department": "Research"})
+	for _, row := range []client.TableRow{
+		{"date": date(-400), "department": "Engineering", "jobTitle": "Engineer"},
+		{"date": date(-1), "department": "Research", "jobTitle": "Engineer"},
+		{"date": date(-1), "department": "Research", "jobTitle": "Researcher"},
+		{"date": date(30), "department": "Sales", "jobTitle": "Researcher"},
+	} {
+		server.AddTableRow(jobInfoTable, ada, row)
+	}
+
+	newConnector := func(t *testing.T, opts ...Option) *BambooHr {
+		opts = append([]Option{
+			WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+			WithGroupByFields([]string{"department"}),
+		}, opts...)
+		connector, err := New(ctx, "mock-company", "mock-access-token", opts...)
+		require.Nil(t, err)
+		return connector
+	}
+	eventIds := func(events []*v2.Event) []string {
+		rv := make([]string, 0, len(events))
+		for _, event := range events {
+			rv = append(rv, event.Id)
+		}
+		return rv
+	}
+
+	t.Run("should not report events unless enabled", func(t *testing.T) {
+		events, _, _, err := newConnector(t).ListEvents(ctx, nil, &pagination.StreamToken{})
+		require.Nil(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("should report changes that took effect", func(t *testing.T) {
+		events, _, _, err := newConnector(t, WithJobChangeEvents()).ListEvents(
+			ctx,
+			timestamppb.New(today.AddDate(0, 0, -7)),
+			&pagination.StreamToken{},
+		)
+		require.Nil(t, err)
+		prefix := "jobInfo:" + ada + ":" + date(-1) + ":department"
+		require.Equal(t, []string{prefix + ":grant", prefix + ":revoke"}, eventIds(events))
+
+		grant := events[0].GetGrantEvent().GetGrant()
+		require.Equal(t, "department:2:member", grant.Entitlement.Id)
+		require.Equal(t, ada, grant.Principal.Id.Resource)
+		revoke := events[1].GetRevokeEvent()
+		require.Equal(t, "department:1:member", revoke.Entitlement.Id)
+		require.Equal(t, ada, revoke.Principal.Id.Resource)
+	})
+
+	t.Run("should only report changes since the cursor", func(t *testing.T) {
+		connector := newConnector(t, WithJobChangeEvents())
+		events, streamState, _, err := connector.ListEvents(ctx, nil, &pagination.StreamToken{})
+		require.Nil(t, err)
+		require.Len(t, events, 3)
+		require.Equal(t, date(0), streamState.Cursor)
+
+		events, _, _, err = connector.ListEvents(ctx, nil, &pagination.StreamToken{Cursor: streamState.Cursor})
+		require.Nil(t, err)
+		require.Empty(t, events)
+
+		events, _, _, err = connector.ListEvents(ctx, nil, &pagination.StreamToken{Cursor: date(-1)})
+		require.Nil(t, err)
+		require.Len(t, events, 2)
+
+		_, _, _, err = connector.ListEvents(ctx, nil, &pagination.StreamToken{Cursor: "yesterday"})
+		require.ErrorContains(t, err, "invalid event cursor")
+	})
+
+	t.Run("should report changes of fields configured by id", func(t *testing.T) {
+		events, _, _, err := newConnector(t, WithJobChangeEvents(), WithGroupByFields([]string{"4"})).ListEvents(
+			ctx,
+			timestamppb.New(today.AddDate(0, 0, -7)),
+			&pagination.StreamToken{},
+		)
+		require.Nil(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "4:2:member", events[0].GetGrantEvent().GetGrant().Entitlement.Id)
+	})
+
+	t.Run("should report the whole history without a start", func(t *testing.T) {
+		events, _, _, err := newConnector(t, WithJobChangeEvents()).ListEvents(ctx, nil, &pagination.StreamToken{})
+		require.Nil(t, err)
+		require.Len(t, events, 3)
+		require.Equal(t, "jobInfo:"+ada+":"+date(-400)+":department:grant", events[0].Id)
+	})
+
+	// profile lists the connector's users and returns the only user's
+	// profile, along with whether the jobInfo table was read.
+	profile := func(t *testing.T, connector *BambooHr) (map[string]interface{}, bool) {
+		requests := len(server.Requests())
+		resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		userTrait, err := resource.GetUserTrait(resources[0])
+		require.Nil(t, err)
+		readJobInfo := false
+		for _, request := range server.Requests()[requests:] {
+			if strings.HasSuffix(request, "/"+jobInfoTable) {
+				readJobInfo = true
+			}
+		}
+		return userTrait.Profile.AsMap(), readJobInfo
+	}
+
+	t.Run("should add pending changes to the profile", func(t *testing.T) {
+		userProfile, readJobInfo := profile(t, newConnector(t, WithPendingJobChanges()))
+		require.True(t, readJobInfo)
+		require.Equal(t, "Sales", userProfile["pendingDepartment"])
+		require.Equal(t, date(30), userProfile["pendingEffectiveDate"])
+	})
+
+	t.Run("should not read job history unless enabled", func(t *testing.T) {
+		userProfile, readJobInfo := profile(t, newConnector(t))
+		require.False(t, readJobInfo)
+		require.NotContains(t, userProfile, "pendingDepartment")
+	})
+}
+
+func TestEmploymentHistory(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.AddListField(&client.ListField{
+		FieldId: "4",
+		Alias:   "department",
+		Name:    "Department",
+		Options: []*client.ListOption{
+			{Id: "1", Name: "Finance", Archived: "no"},
+			{Id: "2", Name: "Engineering", Archived: "no"},
+		},
+	})
+	ada := server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace", "department": "Engineering"})
+	server.AddTableRow(jobInfoTable, ada, client.TableRow{"date": "2019-01-01", "department": "Finance", "jobTitle": "Analyst", "payRate": "100"})
+	server.AddTableRow(jobInfoTable, ada, client.TableRow{"date": "2021-06-01", "department": "Engineering", "jobTitle": "Engineer"})
+	server.AddTableRow(employmentStatusTable, ada, client.TableRow{"date": "2019-01-01", "employmentStatus": "Full-Time"})
+	server.AddTableRow(employmentStatusTable, ada, client.TableRow{"date": "2020-03-31", "employmentStatus": "Terminated"})
+	server.AddTableRow(employmentStatusTable, ada, client.TableRow{"date": "2021-06-01", "employmentStatus": "Full-Time"})
+
+	connector, err := New(
+		ctx,
+		"mock-company",
+		"mock-access-token",
+		WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+		WithGroupByFields([]string{"department"}),
+		WithEmploymentHistory(),
+		WithJobChangeEvents(),
+	)
+	require.Nil(t, err)
+
+	t.Run("should add the history and rehires to the profile", func(t *testing.T) {
+		resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		userTrait, err := resource.GetUserTrait(resources[0])
+		require.Nil(t, err)
+
+		profile := userTrait.Profile.AsMap()
+		require.Equal(t, []interface{}{"2019-01-01", "2021-06-01"}, profile["hireDates"])
+		require.Equal(t, true, profile["rehired"])
+		require.Equal(t, []interface{}{
+			map[string]interface{}{"table": "jobInfo", "effectiveDate": "2019-01-01", "department": "Finance", "jobTitle": "Analyst"},
+			map[string]interface{}{"table": "employmentStatus", "effectiveDate": "2019-01-01", "employmentStatus": "Full-Time"},
+			map[string]interface{}{"table": "employmentStatus", "effectiveDate": "2020-03-31", "employmentStatus": "Terminated"},
+			map[string]interface{}{"table": "jobInfo", "effectiveDate": "2021-06-01", "department": "Engineering", "jobTitle": "Engineer"},
+			map[string]interface{}{"table": "employmentStatus", "effectiveDate": "2021-06-01", "employmentStatus": "Full-Time"},
+		}, profile["employmentHistory"])
+	})
+
+	t.Run("should revoke groups on termination and grant them on rehire", func(t *testing.T) {
+		events, _, _, err := connector.ListEvents(ctx, nil, &pagination.StreamToken{})
+		require.Nil(t, err)
+
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.Id)
+		}
+		require.Equal(t, []string{
+			"employmentStatus:" + ada + ":2019-01-01:employment:hire",
+			"jobInfo:" + ada + ":2019-01-01:department:grant",
+			"employmentStatus:" + ada + ":2020-03-31:department:revoke",
+			"employmentStatus:" + ada + ":2020-03-31:employment:termination",
+			"employmentStatus:" + ada + ":2021-06-01:department:grant",
+			"employmentStatus:" + ada + ":2021-06-01:employment:rehire",
+		}, ids)
+		require.Equal(t, "department:1:member", events[2].GetRevokeEvent().Entitlement.Id)
+		require.Equal(t, "department:2:member", events[4].GetGrantEvent().Grant.Entitlement.Id)
+	})
+
+	t.Run("should report hires, terminations and rehires without group-by fields", func(t *testing.T) {
+		connector, err := New(
+			ctx,
+			"mock-company",
+			"mock-access-token",
+			WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+			WithJobChangeEvents(),
+		)
+		require.Nil(t, err)
+		events, _, _, err := connector.ListEvents(ctx, nil, &pagination.StreamToken{})
+		require.Nil(t, err)
+		require.Len(t, events, 3)
+		require.Equal(t, "employment:employed:member", events[0].GetGrantEvent().Grant.Entitlement.Id)
+		require.Equal(t, "employment:employed:member", events[1].GetRevokeEvent().Entitlement.Id)
+		require.Equal(t, ada, events[2].GetGrantEvent().Grant.Principal.Id.Resource)
+
+		// The Employment group the events refer to is synced, with the
+		// currently employed members.
+		var employment *EmploymentResourceType
+		for _, syncer := range connector.ResourceSyncers(ctx) {
+			if syncer.ResourceType(ctx).Id == resourceTypeEmployment.Id {
+				employment = syncer.(*EmploymentResourceType)
+			}
+		}
+		require.NotNil(t, employment)
+		resources, _, _, err := employment.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		grants, _, _, err := employment.Grants(ctx, resources[0], &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, grants, 1)
+		require.Equal(t, ada, grants[0].Principal.Id.Resource)
+	})
+
+	t.Run("should report terminations of employees that are not synced", func(t *testing.T) {
+		server := test.NewFakeServer()
+		defer server.Close()
+		server.AddListField(&client.ListField{
+			FieldId: "4",
+			Alias:   "department",
+			Name:    "Department",
+			Options: []*client.ListOption{{Id: "1", Name: "Finance", Archived: "no"}},
+		})
+		grace := server.AddEmployee(map[string]string{
+			"firstName":       "Grace",
+			"lastName":        "Hopper",
+			"department":      "Finance",
+			"status":          "Inactive",
+			"terminationDate": "2020-03-31",
+		})
+		server.AddTableRow(jobInfoTable, grace, client.TableRow{"date": "2019-01-01", "department": "Finance"})
+		server.AddTableRow(employmentStatusTable, grace, client.TableRow{"date": "2019-01-01", "employmentStatus": "Full-Time"})
+		server.AddTableRow(employmentStatusTable, grace, client.TableRow{"date": "2020-03-31", "employmentStatus": "Terminated"})
+
+		for _, dataSource := range []string{client.DataSourceCustomReport, client.DataSourceDatasets} {
+			connector, err := New(
+				ctx,
+				"mock-company",
+				"mock-access-token",
+				WithClient(fakeServerClient(t, server, dataSource)),
+				WithGroupByFields([]string{"department"}),
+				WithTerminatedEmployees(false, 0),
+				WithJobChangeEvents(),
+			)
+			require.Nil(t, err)
+			events, _, _, err := connector.ListEvents(ctx, nil, &pagination.StreamToken{})
+			require.Nil(t, err)
+
+			ids := make([]string, 0, len(events))
+			for _, event := range events {
+				ids = append(ids, event.Id)
+			}
+			require.Equal(t, []string{
+				"employmentStatus:" + grace + ":2019-01-01:employment:hire",
+				"employmentStatus:" + grace + ":2020-03-31:employment:termination",
+			}, ids, dataSource)
+			require.Equal(t, "employment:employed:member", events[1].GetRevokeEvent().Entitlement.Id)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const listFieldMemberEntitlement = "member"
+
+var nonResourceTypeIdChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ListFieldResourceType syncs one group per option of a BambooHR list field,
+// e.g. "Cost Center", with employees as members.
+type ListFieldResourceType struct {
+	resourceType *v2.ResourceType
+	field        string
+	directory    *directory
+	// hierarchy, when set, can nest another list field's groups in these.
+	hierarchy *listFieldHierarchy
+}
+
+func (o *ListFieldResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// listField finds the configured field in /meta/lists by alias, name or id.
+func (o *ListFieldResourceType) listField(ctx context.Context) (*client.ListField, *v2.RateLimitDescription, error) {
+	return o.directory.listField(ctx, o.field)
+}
+
+// List returns a group for every option of the field. Archived options are
+// kept and marked inactive so that historical grants still resolve.
+func (o *ListFieldResourceType) List(
+	ctx context.Context,
+	parentResourceID *v2.ResourceId,
+	_ *pagination.Token,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if listedAsAppChild(parentResourceID) {
+		return nil, "", nil, nil
+	}
+
+	list, ratelimitData, err := o.listField(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	rv := make([]*v2.Resource, 0)
+	for _, option := range list.Options {
+		newResource, err := o.listOptionResource(list, option)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, newResource)
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func (o *ListFieldResourceType) Entitlements(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Entitlement,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	expansion, ratelimitData, err := o.hierarchy.expansion(ctx, o)
+	if err != nil {
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+	grantableTo := []*v2.ResourceType{resourceTypeUser}
+	if expansion != nil {
+		grantableTo = append(grantableTo, expansion.child.resourceType)
+	}
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			listFieldMemberEntitlement,
+			entitlement.WithGrantableTo(grantableTo...),
+			entitlement.WithDisplayName(fmt.Sprintf("%s %s Member", resource.DisplayName, o.resourceType.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Has %s set to %s", o.resourceType.DisplayName, resource.DisplayName)),
+		),
+	}, "", WithRateLimitAnnotations(ratelimitData), nil
+}
+
+func (o *ListFieldResourceType) Grants(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Grant,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	list, ratelimitData, err := o.listField(ctx)
+	if err != nil {
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+
+	var optionName string
+	for _, option := range list.Options {
+		if option.Id.String() == resource.Id.Resource {
+			optionName = option.Name
+		}
+	}
+
+	expansion, ratelimitData, err := o.hierarchy.expansion(ctx, o)
+	if err != nil {
+		return nil, "", WithRateLimitAnnotations(ratelimitData), err
+	}
+	reportField := list.ReportField()
+	if expansion != nil && optionName != "" {
+		rv, outputAnnotations, err := o.expandedGrants(ctx, expansion, resource, reportField, optionName)
+		return rv, "", outputAnnotations, err
+	}
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	rv := make([]*v2.Grant, 0)
+	if optionName == "" {
+		return rv, "", outputAnnotations, nil
+	}
+	for _, user := range employees.included {
+		if user.Fields[reportField] != optionName {
+			continue
+		}
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     user.Id,
+		}
+		rv = append(rv, grant.NewGrant(resource, listFieldMemberEntitlement, principal))
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+// listFieldResourceTypeId turns a field name such as "Cost Center" into a
+// resource type id such as "cost_center".
+func listFieldResourceTypeId(field string) string {
+	return strings.Trim(nonResourceTypeIdChars.ReplaceAllString(strings.ToLower(field), "_"), "_")
+}
+
+func listFieldBuilder(directory *directory, field string) *ListFieldResourceType {
+	return &ListFieldResourceType{
+		resourceType: &v2.ResourceType{
+			Id:          listFieldResourceTypeId(field),
+			DisplayName: field,
+			Traits: []v2.ResourceType_Trait{
+				v2.ResourceType_TRAIT_GROUP,
+			},
+		},
+		field:     field,
+		directory: directory,
+	}
+}
+
+// listOptionResource converts a list field option into a group Resource.
+func (o *ListFieldResourceType) listOptionResource(
+	list *client.ListField,
+	option *client.ListOption,
+) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"field_id":   list.FieldId.String(),
+		"field_name": list.Name,
+		"option_id":  option.Id.String(),
+		"active":     !option.IsArchived(),
+		"archived":   option.IsArchived(),
+	}
+	if option.IsArchived() && option.ArchivedDate != "" {
+		profile["archived_date"] = option.ArchivedDate
+	}
+
+	return resource.NewGroupResource(
+		option.Name,
+		o.resourceType,
+		option.Id.String(),
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(profile),
+		},
+	)
+}
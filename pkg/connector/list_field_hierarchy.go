@@ -0,0 +1,207 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+)
+
+// Aliases of the BambooHR list fields that are nested in each other. They are
+// also the names of the jobInfo columns that hold them.
+const (
+	departmentField = "department"
+	divisionField   = "division"
+)
+
+// listFieldHierarchy nests the department groups in the division groups when
+// both fields are synced. Group-by fields can name them by alias, name or id,
+// so which syncers hold the department and the division is only known once
+// /meta/lists has been read.
+type listFieldHierarchy struct {
+	directory *directory
+	fields    []*ListFieldResourceType
+	// parents maps lower-cased department names to division names, on top
+	// of the mapping inferred from employees.
+	parents map[string]string
+}
+
+// fieldWithAlias returns the syncer whose field resolves to the list field
+// with the given alias, or nil when none does.
+func (h *listFieldHierarchy) fieldWithAlias(
+	ctx context.Context,
+	alias string,
+) (*ListFieldResourceType, *v2.RateLimitDescription, error) {
+	lists, ratelimitData, err := h.directory.listFields(ctx)
+	if err != nil {
+		return nil, ratelimitData, err
+	}
+	for _, field := range h.fields {
+		list := findListField(lists, field.field)
+		if list != nil && strings.EqualFold(list.Alias, alias) {
+			return field, ratelimitData, nil
+		}
+	}
+	return nil, ratelimitData, nil
+}
+
+// expansion returns the expansion of a field's groups: the division's groups
+// nest the department's. It is nil for every other field, and when only one
+// of the two is synced.
+func (h *listFieldHierarchy) expansion(
+	ctx context.Context,
+	field *ListFieldResourceType,
+) (*listFieldExpansion, *v2.RateLimitDescription, error) {
+	if h == nil {
+		return nil, nil, nil
+	}
+	division, ratelimitData, err := h.fieldWithAlias(ctx, divisionField)
+	if err != nil || division != field {
+		return nil, ratelimitData, err
+	}
+	department, ratelimitData, err := h.fieldWithAlias(ctx, departmentField)
+	if err != nil || department == nil {
+		return nil, ratelimitData, err
+	}
+	return &listFieldExpansion{child: department, parents: h.parents}, ratelimitData, nil
+}
+
+// validate checks that department divisions, when configured, have both the
+// department and the division to apply to.
+func (h *listFieldHierarchy) validate(ctx context.Context) error {
+	if len(h.parents) == 0 {
+		return nil
+	}
+	for _, alias := range []string{departmentField, divisionField} {
+		field, _, err := h.fieldWithAlias(ctx, alias)
+		if err != nil {
+			return err
+		}
+		if field == nil {
+			return fmt.Errorf("bamboohr-connector: department divisions require %q in the group-by fields", alias)
+		}
+	}
+	return nil
+}
+
+// listFieldExpansion nests the groups of one list field in the groups of
+// another, such as departments in divisions. A parent group is granted to
+// each child group that belongs to it, with a GrantExpandable annotation, so
+// the child's members become the parent's members without the grant being
+// computed twice.
+type listFieldExpansion struct {
+	child *ListFieldResourceType
+	// parents maps child option names to parent option names. Children that
+	// are not in it belong to a parent when every employee with the child
+	// option has that parent option.
+	parents map[string]string
+}
+
+// ParseDepartmentDivisions parses <department>=<division> entries, keyed by
+// lower-cased department name.
+func ParseDepartmentDivisions(entries []string) (map[string]string, error) {
+	rv := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		department, division, ok := strings.Cut(entry, "=")
+		department = strings.TrimSpace(department)
+		division = strings.TrimSpace(division)
+		if !ok || department == "" || division == "" {
+			return nil, fmt.Errorf("bamboohr-connector: invalid department division %q, expected <department>=<division>", entry)
+		}
+		rv[strings.ToLower(department)] = division
+	}
+	return rv, nil
+}
+
+// expandedGrants returns the grants of a parent group: one expandable grant
+// per child group that belongs to it, and a direct grant for each member
+// whose child option does not belong to it.
+func (o *ListFieldResourceType) expandedGrants(
+	ctx context.Context,
+	expansion *listFieldExpansion,
+	resource *v2.Resource,
+	reportField string,
+	optionName string,
+) ([]*v2.Grant, annotations.Annotations, error) {
+	child := expansion.child
+	childList, ratelimitData, err := child.listField(ctx)
+	if err != nil {
+		return nil, WithRateLimitAnnotations(ratelimitData), err
+	}
+	childReportField := childList.ReportField()
+
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, outputAnnotations, err
+	}
+	// Parents are inferred from every employee, but only synced employees
+	// are granted membership.
+	users := employees.all
+
+	// Infer each child option's parent from employees, keeping only the
+	// children whose employees all share one parent.
+	inferred := make(map[string]string)
+	ambiguous := make(map[string]bool)
+	for _, user := range users {
+		childName := user.Fields[childReportField]
+		if childName == "" {
+			continue
+		}
+		parentName := user.Fields[reportField]
+		if existing, ok := inferred[childName]; parentName == "" || (ok && existing != parentName) {
+			ambiguous[childName] = true
+		}
+		inferred[childName] = parentName
+	}
+	parentOf := func(childName string) string {
+		if parentName, ok := expansion.parents[strings.ToLower(childName)]; ok {
+			return parentName
+		}
+		if ambiguous[childName] {
+			return ""
+		}
+		return inferred[childName]
+	}
+
+	rv := make([]*v2.Grant, 0)
+	expanded := make(map[string]bool)
+	for _, option := range childList.Options {
+		if !strings.EqualFold(parentOf(option.Name), optionName) {
+			continue
+		}
+		expanded[option.Name] = true
+
+		principal := &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: child.resourceType.Id,
+				Resource:     option.Id.String(),
+			},
+		}
+		rv = append(rv, grant.NewGrant(
+			resource,
+			listFieldMemberEntitlement,
+			principal.Id,
+			grant.WithAnnotation(&v2.GrantExpandable{
+				EntitlementIds: []string{entitlement.NewEntitlementID(principal, listFieldMemberEntitlement)},
+			}),
+		))
+	}
+
+	for _, user := range employees.included {
+		if user.Fields[reportField] != optionName || expanded[user.Fields[childReportField]] {
+			continue
+		}
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     user.Id,
+		}
+		rv = append(rv, grant.NewGrant(resource, listFieldMemberEntitlement, principal))
+	}
+
+	return rv, outputAnnotations, nil
+}
@@ -0,0 +1,152 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepartmentDivisions(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.AddListField(&client.ListField{
+		FieldId: "4",
+		Alias:   "department",
+		Name:    "Department",
+		Options: []*client.ListOption{
+			{Id: "1", Name: "Engineering", Archived: "no"},
+			{Id: "2", Name: "Research", Archived: "no"},
+			{Id: "3", Name: "Sales", Archived: "no"},
+			{Id: "4", Name: "Operations", Archived: "no"},
+		},
+	})
+	server.AddListField(&client.ListField{
+		FieldId: "5",
+		Alias:   "division",
+		Name:    "Division",
+		Options: []*client.ListOption{
+			{Id: "10", Name: "R&D", Archived: "no"},
+			{Id: "11", Name: "Go To Market", Archived: "no"},
+		},
+	})
+	employee := func(name string, department string, division string) string {
+		return server.AddEmployee(map[string]string{
+			"firstName":  name,
+			"lastName":   "Test",
+			"workEmail":  name + "@example.com",
+			"department": department,
+			"division":   division,
+		})
+	}
+	engineer := employee("engineer", "Engineering", "R&D")
+	developer := employee("developer", "Engineering", "R&D")
+	researcher := employee("researcher", "Research", "R&D")
+	employee("seller", "Sales", "")
+	operator := employee("operator", "Operations", "R&D")
+	dispatcher := employee("dispatcher", "Operations", "Go To Market")
+
+	newConnector := func(t *testing.T, groupByFields ...string) *BambooHr {
+		connector, err := New(
+			ctx,
+			"mock-company",
+			"mock-access-token",
+			WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+			WithGroupByFields(groupByFields),
+			WithDepartmentDivisions([]string{"Sales=Go To Market"}),
+		)
+		require.Nil(t, err)
+		return connector
+	}
+	// divisionPrincipals returns the principals of each division's grants,
+	// with the division and department synced as the given resource types.
+	divisionPrincipals := func(t *testing.T, connector *BambooHr, divisionId string, departmentId string) map[string][]string {
+		var division *ListFieldResourceType
+		for _, syncer := range connector.ResourceSyncers(ctx) {
+			if syncer.ResourceType(ctx).Id == divisionId {
+				division = syncer.(*ListFieldResourceType)
+			}
+		}
+		require.NotNil(t, division)
+
+		entitlements, _, _, err := division.Entitlements(ctx, &v2.Resource{Id: &v2.ResourceId{ResourceType: divisionId, Resource: "10"}}, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, entitlements[0].GrantableTo, 2)
+		require.Equal(t, departmentId, entitlements[0].GrantableTo[1].Id)
+
+		divisions, _, _, err := division.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		principals := make(map[string][]string)
+		for _, resource := range divisions {
+			grants, _, _, err := division.Grants(ctx, resource, &pagination.Token{})
+			require.Nil(t, err)
+			for _, grant := range grants {
+				principal := grant.Principal.Id.ResourceType + ":" + grant.Principal.Id.Resource
+				expandable := &v2.GrantExpandable{}
+				grantAnnotations := annotations.Annotations(grant.Annotations)
+				ok, err := grantAnnotations.Pick(expandable)
+				require.Nil(t, err)
+				if ok {
+					require.Equal(t, []string{principal + ":member"}, expandable.EntitlementIds)
+				}
+				principals[resource.DisplayName] = append(principals[resource.DisplayName], principal)
+			}
+		}
+		return principals
+	}
+
+	t.Run("should require both group-by fields", func(t *testing.T) {
+		connector := newConnector(t, "division")
+		_, err := connector.Validate(ctx)
+		require.ErrorContains(t, err, `"department"`)
+	})
+
+	t.Run("should grant divisions to the departments in them", func(t *testing.T) {
+		connector := newConnector(t, "department", "division")
+		_, err := connector.Validate(ctx)
+		require.Nil(t, err)
+
+		principals := divisionPrincipals(t, connector, "division", "department")
+		require.Equal(t, []string{"department:1", "department:2", "user:" + operator}, principals["R&D"])
+		require.Equal(t, []string{"department:3", "user:" + dispatcher}, principals["Go To Market"])
+	})
+
+	t.Run("should match fields configured by id or name", func(t *testing.T) {
+		connector := newConnector(t, "4", "Division")
+		_, err := connector.Validate(ctx)
+		require.Nil(t, err)
+
+		principals := divisionPrincipals(t, connector, "division", "4")
+		require.Equal(t, []string{"4:1", "4:2", "user:" + operator}, principals["R&D"])
+		require.Equal(t, []string{"4:3", "user:" + dispatcher}, principals["Go To Market"])
+	})
+
+	t.Run("should expand division membership in a sync", func(t *testing.T) {
+		connector, err := connectorbuilder.NewConnector(ctx, newConnector(t, "department", "division"))
+		require.Nil(t, err)
+		snapshot := make(map[string][]map[string]interface{})
+		require.Nil(t, json.Unmarshal(test.SyncSnapshot(ctx, t, connector), &snapshot))
+
+		members := make([]string, 0)
+		for _, grant := range snapshot["grants"] {
+			entitlement, _ := json.Marshal(grant["entitlement"].(map[string]interface{})["id"])
+			if string(entitlement) != `"division:10:member"` {
+				continue
+			}
+			principal := grant["principal"].(map[string]interface{})["id"].(map[string]interface{})
+			if principal["resourceType"] == resourceTypeUser.Id {
+				members = append(members, principal["resource"].(string))
+			}
+		}
+		require.ElementsMatch(t, []string{engineer, developer, researcher, operator}, members)
+	})
+}
@@ -0,0 +1,75 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFields(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	bambooHRClient, err := client.New(
+		ctx,
+		"mock-access-token",
+		"mock-company",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bambooHRClient.SetBaseUrl(server.URL)
+	c := listFieldBuilder(newDirectory(bambooHRClient, nil, userConfig{}, nil), "Cost Center")
+	require.Equal(t, "cost_center", c.ResourceType(ctx).Id)
+
+	resources, _, listAnnotations, err := c.List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	test.AssertNoRatelimitAnnotations(t, listAnnotations)
+	require.Len(t, resources, 2)
+
+	byName := make(map[string]*v2.Resource)
+	for _, r := range resources {
+		byName[r.DisplayName] = r
+	}
+
+	t.Run("should keep archived options as inactive groups", func(t *testing.T) {
+		groupTrait, err := resource.GetGroupTrait(byName["Research"])
+		require.Nil(t, err)
+		active, ok := groupTrait.Profile.AsMap()["active"]
+		require.True(t, ok)
+		require.Equal(t, false, active)
+	})
+
+	t.Run("should grant membership from report data", func(t *testing.T) {
+		grants, _, _, err := c.Grants(ctx, byName["Engineering"], &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, grants, 1)
+		require.Equal(t, "id", grants[0].Principal.Id.Resource)
+
+		grants, _, _, err = c.Grants(ctx, byName["Research"], &pagination.Token{})
+		require.Nil(t, err)
+		require.Empty(t, grants)
+	})
+
+	t.Run("should reject colliding resource type ids", func(t *testing.T) {
+		for _, opts := range [][]Option{
+			{WithGroupByFields([]string{"User"})},
+			{WithGroupByFields([]string{"Cost Center", "cost-center"})},
+			{
+				WithCustomTables([]*CustomTableMapping{{Table: "customCostCenters", ResourceTypeId: "cost_center", KeyColumn: "id"}}),
+				WithGroupByFields([]string{"Cost Center"}),
+			},
+		} {
+			_, err := New(ctx, "mock-company", "mock-access-token", opts...)
+			require.ErrorContains(t, err, "conflicts with")
+		}
+	})
+}
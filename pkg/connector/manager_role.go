@@ -0,0 +1,156 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const (
+	managerRoleAssignedEntitlement = "assigned"
+	peopleManagerRoleId            = "people_manager"
+	seniorManagerRoleId            = "senior_manager"
+)
+
+// ManagerRoleResourceType syncs roles derived from the org chart rather than
+// read from BambooHR: people_manager, granted to active employees with at
+// least one active direct report, and, when a threshold is set,
+// senior_manager, granted to active employees with more active indirect
+// reports than the threshold. Indirect reports are everyone below an
+// employee's direct reports.
+type ManagerRoleResourceType struct {
+	resourceType    *v2.ResourceType
+	directory       *directory
+	seniorThreshold int
+}
+
+func (o *ManagerRoleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func (o *ManagerRoleResourceType) List(
+	_ context.Context,
+	_ *v2.ResourceId,
+	_ *pagination.Token,
+) ([]*v2.Resource, string, annotations.Annotations, error) {
+	people, err := resource.NewRoleResource(
+		"People Manager",
+		resourceTypeManagerRole,
+		peopleManagerRoleId,
+		[]resource.RoleTraitOption{
+			resource.WithRoleProfile(map[string]interface{}{"minDirectReports": 1}),
+		},
+	)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	rv := []*v2.Resource{people}
+
+	if o.seniorThreshold > 0 {
+		senior, err := resource.NewRoleResource(
+			"Senior Manager",
+			resourceTypeManagerRole,
+			seniorManagerRoleId,
+			[]resource.RoleTraitOption{
+				resource.WithRoleProfile(map[string]interface{}{"minIndirectReports": o.seniorThreshold + 1}),
+			},
+		)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, senior)
+	}
+
+	return rv, "", nil, nil
+}
+
+func (o *ManagerRoleResourceType) Entitlements(
+	_ context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Entitlement,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			managerRoleAssignedEntitlement,
+			entitlement.WithGrantableTo(resourceTypeUser),
+			entitlement.WithDisplayName(resource.DisplayName),
+			entitlement.WithDescription("Has the "+resource.DisplayName+" role"),
+		),
+	}, "", nil, nil
+}
+
+// Grants assigns the role to the synced employees that qualify for it. Reports
+// are counted over every employee the report returned, so a manager whose
+// reports are filtered out of the sync still qualifies.
+func (o *ManagerRoleResourceType) Grants(
+	ctx context.Context,
+	resource *v2.Resource,
+	_ *pagination.Token,
+) (
+	[]*v2.Grant,
+	string,
+	annotations.Annotations,
+	error,
+) {
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
+	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	rv := make([]*v2.Grant, 0)
+	for _, user := range employees.included {
+		if !user.IsActive() {
+			continue
+		}
+		direct, indirect := employees.org.reports(user.Id)
+		switch resource.Id.Resource {
+		case peopleManagerRoleId:
+			if direct == 0 {
+				continue
+			}
+		case seniorManagerRoleId:
+			if o.seniorThreshold <= 0 || indirect <= o.seniorThreshold {
+				continue
+			}
+		default:
+			return nil, "", outputAnnotations, fmt.Errorf("bamboohr-connector: unknown manager role %q", resource.Id.Resource)
+		}
+
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     user.Id,
+		}
+		rv = append(rv, grant.NewGrant(
+			resource,
+			managerRoleAssignedEntitlement,
+			principal,
+			grant.WithGrantMetadata(map[string]interface{}{
+				"directReports":   direct,
+				"indirectReports": indirect,
+			}),
+		))
+	}
+
+	return rv, "", outputAnnotations, nil
+}
+
+func managerRoleBuilder(directory *directory, seniorThreshold int) *ManagerRoleResourceType {
+	return &ManagerRoleResourceType{
+		resourceType:    resourceTypeManagerRole,
+		directory:       directory,
+		seniorThreshold: seniorThreshold,
+	}
+}
@@ -0,0 +1,75 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerRoles(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+
+	employee := func(name string, supervisor string, status string) string {
+		return server.AddEmployee(map[string]string{
+			"firstName":     name,
+			"lastName":      "Test",
+			"workEmail":     name + "@example.com",
+			"status":        status,
+			"supervisorEId": supervisor,
+		})
+	}
+	ceo := employee("ceo", "", "Active")
+	vp := employee("vp", ceo, "Active")
+	departedVp := employee("departedVp", ceo, "Inactive")
+	employee("engineer", vp, "Active")
+	lead := employee("lead", vp, "Active")
+	employee("intern", lead, "Active")
+	employee("contractor", departedVp, "Active")
+	employee("leaver", lead, "Inactive")
+
+	grantees := func(builder *ManagerRoleResourceType) map[string][]string {
+		roles, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		rv := make(map[string][]string)
+		for _, role := range roles {
+			grants, _, _, err := builder.Grants(ctx, role, &pagination.Token{})
+			require.Nil(t, err)
+			rv[role.Id.Resource] = make([]string, 0)
+			for _, grant := range grants {
+				rv[role.Id.Resource] = append(rv[role.Id.Resource], grant.Principal.Id.Resource)
+			}
+		}
+		return rv
+	}
+
+	t.Run("should grant people_manager to managers of active employees", func(t *testing.T) {
+		builder := managerRoleBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{}, nil), 0)
+		require.Equal(t, map[string][]string{
+			peopleManagerRoleId: {ceo, vp, lead},
+		}, grantees(builder))
+	})
+
+	t.Run("should grant senior_manager above the threshold", func(t *testing.T) {
+		// The ceo's active indirect reports are the engineer, lead, intern and
+		// the contractor under the departed vp. The vp only has the intern.
+		builder := managerRoleBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{}, nil), 3)
+		require.Equal(t, []string{ceo}, grantees(builder)[seniorManagerRoleId])
+
+		builder = managerRoleBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{}, nil), 4)
+		require.Empty(t, grantees(builder)[seniorManagerRoleId])
+	})
+
+	t.Run("should only grant to synced employees", func(t *testing.T) {
+		filter, err := ParseUserFilter(`firstName != "ceo"`)
+		require.Nil(t, err)
+		builder := managerRoleBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), nil, userConfig{filter: filter}, nil), 0)
+		require.Equal(t, []string{vp, lead}, grantees(builder)[peopleManagerRoleId])
+	})
+}
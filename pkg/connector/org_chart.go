@@ -0,0 +1,230 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// Kinds of OrgChartIssue.
+const (
+	// OrgChartDanglingSupervisor is an employee whose supervisor is not an
+	// employee.
+	OrgChartDanglingSupervisor = "dangling_supervisor"
+	// OrgChartTerminatedSupervisor is an active employee whose supervisor is
+	// no longer active.
+	OrgChartTerminatedSupervisor = "terminated_supervisor"
+	// OrgChartReportingCycle is a group of employees who, following
+	// supervisors, report to each other.
+	OrgChartReportingCycle = "reporting_cycle"
+)
+
+// OrgChartIssue is a problem found in the org chart. Cycle lists the members
+// of a reporting cycle, starting with EmployeeId and following supervisors.
+type OrgChartIssue struct {
+	Kind          string   `json:"kind"`
+	EmployeeId    string   `json:"employeeId"`
+	SupervisorEId string   `json:"supervisorEId,omitempty"`
+	Cycle         []string `json:"cycle,omitempty"`
+}
+
+// OrgChartReport is the validation report written by WithOrgChartReport.
+type OrgChartReport struct {
+	Employees int              `json:"employees"`
+	Issues    []*OrgChartIssue `json:"issues"`
+}
+
+// orgPosition is where an employee sits in the org chart. managerChain runs
+// from the employee's supervisor up to the top of the chart, and
+// topLevelManager is its last entry, or the employee themselves when no
+// supervisor of theirs is an employee. Chains that end in a reporting cycle
+// have no top level manager.
+type orgPosition struct {
+	managerChain    []string
+	topLevelManager string
+}
+
+// orgChart is the supervisor graph of every employee, including those that
+// are not synced, so that supervisors outside the synced set are still
+// recognized.
+type orgChart struct {
+	employees map[string]*client.User
+	positions map[string]*orgPosition
+	// directReports maps supervisors to the employees reporting to them.
+	directReports map[string][]string
+	cycles        [][]string
+}
+
+func newOrgChart(users []*client.User) *orgChart {
+	rv := &orgChart{
+		employees:     make(map[string]*client.User, len(users)),
+		positions:     make(map[string]*orgPosition, len(users)),
+		directReports: make(map[string][]string),
+	}
+	for _, user := range users {
+		rv.employees[user.Id] = user
+		if user.SupervisorEId != "" {
+			rv.directReports[user.SupervisorEId] = append(rv.directReports[user.SupervisorEId], user.Id)
+		}
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.Id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		rv.positions[id] = rv.walk(id)
+	}
+	return rv
+}
+
+// walk follows supervisors up from an employee and records any reporting
+// cycle it runs into.
+func (o *orgChart) walk(id string) *orgPosition {
+	rv := &orgPosition{managerChain: make([]string, 0)}
+	seen := map[string]int{id: 0}
+	path := []string{id}
+	current := id
+	for {
+		supervisor := o.employees[current].SupervisorEId
+		if supervisor == "" || o.employees[supervisor] == nil {
+			rv.topLevelManager = current
+			return rv
+		}
+		if start, ok := seen[supervisor]; ok {
+			o.addCycle(path[start:])
+			return rv
+		}
+		seen[supervisor] = len(path)
+		path = append(path, supervisor)
+		rv.managerChain = append(rv.managerChain, supervisor)
+		current = supervisor
+	}
+}
+
+func (o *orgChart) addCycle(members []string) {
+	// Rotate the cycle to start at its lowest id, so each cycle is recorded
+	// once however it was reached.
+	lowest := 0
+	for i, member := range members {
+		if member < members[lowest] {
+			lowest = i
+		}
+	}
+	cycle := append(slices.Clone(members[lowest:]), members[:lowest]...)
+	for _, existing := range o.cycles {
+		if slices.Equal(existing, cycle) {
+			return
+		}
+	}
+	o.cycles = append(o.cycles, cycle)
+}
+
+// position returns an employee's place in the chart.
+func (o *orgChart) position(id string) *orgPosition {
+	position, ok := o.positions[id]
+	if !ok {
+		return &orgPosition{managerChain: make([]string, 0), topLevelManager: id}
+	}
+	return position
+}
+
+// reports counts an employee's active direct reports, and their active
+// indirect reports: everyone below their direct reports.
+func (o *orgChart) reports(id string) (int, int) {
+	direct := 0
+	visited := map[string]bool{id: true}
+	next := make([]string, 0)
+	for _, report := range o.directReports[id] {
+		visited[report] = true
+		if o.employees[report].IsActive() {
+			direct++
+		}
+		next = append(next, o.directReports[report]...)
+	}
+
+	indirect := 0
+	for len(next) > 0 {
+		report := next[0]
+		next = next[1:]
+		if visited[report] {
+			continue
+		}
+		visited[report] = true
+		if o.employees[report].IsActive() {
+			indirect++
+		}
+		next = append(next, o.directReports[report]...)
+	}
+	return direct, indirect
+}
+
+// issues returns the problems affecting the synced employees.
+func (o *orgChart) issues(synced []*client.User) []*OrgChartIssue {
+	rv := make([]*OrgChartIssue, 0)
+	syncedIds := make(map[string]bool, len(synced))
+	for _, user := range synced {
+		syncedIds[user.Id] = true
+		if user.SupervisorEId == "" {
+			continue
+		}
+		supervisor := o.employees[user.SupervisorEId]
+		switch {
+		case supervisor == nil:
+			rv = append(rv, &OrgChartIssue{Kind: OrgChartDanglingSupervisor, EmployeeId: user.Id, SupervisorEId: user.SupervisorEId})
+		case supervisor != nil && user.IsActive() && !supervisor.IsActive():
+			rv = append(rv, &OrgChartIssue{Kind: OrgChartTerminatedSupervisor, EmployeeId: user.Id, SupervisorEId: user.SupervisorEId})
+		}
+	}
+	for _, cycle := range o.cycles {
+		if slices.ContainsFunc(cycle, func(id string) bool { return syncedIds[id] }) {
+			rv = append(rv, &OrgChartIssue{Kind: OrgChartReportingCycle, EmployeeId: cycle[0], Cycle: cycle})
+		}
+	}
+	return rv
+}
+
+// validate logs a warning for each org chart issue affecting the synced
+// employees and, when reportPath is set, writes them to a validation report.
+func (o *orgChart) validate(ctx context.Context, synced []*client.User, reportPath string) error {
+	l := ctxzap.Extract(ctx)
+	issues := o.issues(synced)
+	for _, issue := range issues {
+		switch issue.Kind {
+		case OrgChartDanglingSupervisor:
+			l.Warn(
+				"org chart: supervisor is not an employee",
+				zap.String("employee_id", issue.EmployeeId),
+				zap.String("supervisor_eid", issue.SupervisorEId),
+			)
+		case OrgChartTerminatedSupervisor:
+			l.Warn(
+				"org chart: supervisor is terminated",
+				zap.String("employee_id", issue.EmployeeId),
+				zap.String("supervisor_eid", issue.SupervisorEId),
+			)
+		case OrgChartReportingCycle:
+			l.Warn("org chart: reporting cycle", zap.Strings("employee_ids", issue.Cycle))
+		}
+	}
+
+	if reportPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(&OrgChartReport{Employees: len(synced), Issues: issues}, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(reportPath, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("bamboohr-connector: error writing org chart report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestOrgChart(t *testing.T) {
+	server := test.NewFakeServer()
+	defer server.Close()
+
+	employee := func(name string, supervisor string, status string) string {
+		return server.AddEmployee(map[string]string{
+			"firstName":     name,
+			"lastName":      "Test",
+			"workEmail":     name + "@example.com",
+			"status":        status,
+			"supervisorEId": supervisor,
+		})
+	}
+	ceo := employee("ceo", "", "Active")
+	vp := employee("vp", ceo, "Active")
+	engineer := employee("engineer", vp, "Active")
+	departed := employee("departed", ceo, "Inactive")
+	orphan := employee("orphan", departed, "Active")
+	stray := employee("stray", "404", "Active")
+	first := employee("first", "", "Active")
+	second := employee("second", first, "Active")
+	server.UpdateEmployee(first, map[string]string{"supervisorEId": second})
+
+	reportPath := filepath.Join(t.TempDir(), "org_chart.json")
+	core, logs := observer.New(zapcore.WarnLevel)
+	ctx := ctxzap.ToContext(context.Background(), zap.New(core))
+	connector, err := New(
+		ctx,
+		"mock-company",
+		"mock-access-token",
+		WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+		WithOrgChartReport(reportPath),
+	)
+	require.Nil(t, err)
+
+	resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	profiles := make(map[string]map[string]interface{})
+	for _, r := range resources {
+		userTrait, err := resource.GetUserTrait(r)
+		require.Nil(t, err)
+		profiles[r.Id.Resource] = userTrait.Profile.AsMap()
+	}
+
+	t.Run("should add manager chains to profiles", func(t *testing.T) {
+		testCases := []struct {
+			id       string
+			chain    []interface{}
+			topLevel string
+		}{
+			{ceo, []interface{}{}, ceo},
+			{vp, []interface{}{ceo}, ceo},
+			{engineer, []interface{}{vp, ceo}, ceo},
+			{orphan, []interface{}{departed, ceo}, ceo},
+			{stray, []interface{}{}, stray},
+			{first, []interface{}{second}, ""},
+		}
+		for _, testCase := range testCases {
+			profile := profiles[testCase.id]
+			require.Equal(t, testCase.chain, profile["managerChain"], testCase.id)
+			require.Equal(t, float64(len(testCase.chain)), profile["orgDepth"], testCase.id)
+			require.Equal(t, testCase.topLevel, profile["topLevelManager"], testCase.id)
+		}
+	})
+
+	t.Run("should report org chart issues", func(t *testing.T) {
+		data, err := os.ReadFile(reportPath)
+		require.Nil(t, err)
+		report := &OrgChartReport{}
+		require.Nil(t, json.Unmarshal(data, report))
+
+		require.Equal(t, len(resources), report.Employees)
+		require.ElementsMatch(t, []*OrgChartIssue{
+			{Kind: OrgChartTerminatedSupervisor, EmployeeId: orphan, SupervisorEId: departed},
+			{Kind: OrgChartDanglingSupervisor, EmployeeId: stray, SupervisorEId: "404"},
+			{Kind: OrgChartReportingCycle, EmployeeId: first, Cycle: []string{first, second}},
+		}, report.Issues)
+		require.Equal(t, 3, logs.FilterMessageSnippet("org chart:").Len())
+	})
+
+	t.Run("should see supervisors left out by server-side filters", func(t *testing.T) {
+		for _, dataSource := range []string{client.DataSourceCustomReport, client.DataSourceDatasets} {
+			reportPath := filepath.Join(t.TempDir(), dataSource+".json")
+			connector, err := New(
+				ctx,
+				"mock-company",
+				"mock-access-token",
+				WithClient(fakeServerClient(t, server, dataSource)),
+				WithTerminatedEmployees(false, 0),
+				WithOrgChartReport(reportPath),
+			)
+			require.Nil(t, err)
+			_, _, _, err = userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+			require.Nil(t, err)
+
+			data, err := os.ReadFile(reportPath)
+			require.Nil(t, err)
+			report := &OrgChartReport{}
+			require.Nil(t, json.Unmarshal(data, report))
+			require.Equal(t, len(resources)-1, report.Employees, dataSource)
+			require.ElementsMatch(t, []*OrgChartIssue{
+				{Kind: OrgChartTerminatedSupervisor, EmployeeId: orphan, SupervisorEId: departed},
+				{Kind: OrgChartDanglingSupervisor, EmployeeId: stray, SupervisorEId: "404"},
+				{Kind: OrgChartReportingCycle, EmployeeId: first, Cycle: []string{first, second}},
+			}, report.Issues, dataSource)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestForEachEmployee(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should bound concurrency", func(t *testing.T) {
+		pool, err := client.NewPool(3)
+		require.Nil(t, err)
+
+		var (
+			mu       sync.Mutex
+			inFlight int
+			most     int
+		)
+		release := make(chan struct{})
+		employeeIds := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+		go func() {
+			for range employeeIds {
+				release <- struct{}{}
+			}
+		}()
+		results, err := client.ForEachEmployee(ctx, pool, employeeIds, func(_ context.Context, employeeId string) (string, *v2.RateLimitDescription, error) {
+			mu.Lock()
+			inFlight++
+			most = max(most, inFlight)
+			mu.Unlock()
+			<-release
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return "employee " + employeeId, nil, nil
+		})
+		require.Nil(t, err)
+		require.Len(t, results.Values, len(employeeIds))
+		require.Equal(t, "employee 8", results.Values["8"])
+		require.LessOrEqual(t, most, 3)
+	})
+
+	t.Run("should send single requests on the pool", func(t *testing.T) {
+		pool, err := client.NewPool(1)
+		require.Nil(t, err)
+
+		attempts := 0
+		_, err = pool.Do(ctx, func(_ context.Context) (*v2.RateLimitDescription, error) {
+			attempts++
+			if attempts == 1 {
+				return &v2.RateLimitDescription{ResetAt: timestamppb.Now()}, client.ErrUnavailable
+			}
+			return nil, nil
+		})
+		require.Nil(t, err)
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("should reject a pool without workers", func(t *testing.T) {
+		_, err := client.NewPool(0)
+		require.Error(t, err)
+	})
+}
+
+func TestPerEmployeeCustomTable(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	employees := make([]string, 0)
+	for _, name := range []string{"Ada", "Grace", "Alan"} {
+		id := server.AddEmployee(map[string]string{"firstName": name, "lastName": "Test"})
+		server.AddTableRow("customTraining", id, client.TableRow{"customCourse": "security"})
+		employees = append(employees, id)
+	}
+	ada, grace, alan := employees[0], employees[1], employees[2]
+
+	newBuilder := func(t *testing.T) *CustomTableResourceType {
+		pool, err := client.NewPool(2)
+		require.Nil(t, err)
+		mapping := &CustomTableMapping{
+			Table:          "customTraining",
+			ResourceTypeId: "training",
+			KeyColumn:      "customCourse",
+			PerEmployee:    true,
+		}
+		require.Nil(t, mapping.validate())
+		return customTableBuilder(newDirectory(fakeServerClient(t, server, client.DataSourceCustomReport), pool, userConfig{}, nil), mapping)
+	}
+	security := &v2.Resource{Id: &v2.ResourceId{ResourceType: "training", Resource: "security"}}
+	principals := func(t *testing.T, builder *CustomTableResourceType) []string {
+		grants, _, _, err := builder.Grants(ctx, security, &pagination.Token{})
+		require.Nil(t, err)
+		rv := make([]string, 0, len(grants))
+		for _, grant := range grants {
+			rv = append(rv, grant.Principal.Id.Resource)
+		}
+		return rv
+	}
+
+	t.Run("should read every employee's rows", func(t *testing.T) {
+		resources, _, _, err := newBuilder(t).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, resources, 1)
+		require.ElementsMatch(t, employees, principals(t, newBuilder(t)))
+	})
+
+	t.Run("should read each employee once per sync", func(t *testing.T) {
+		// The mock counts calls, which HTTP requests cannot show since the
+		// SDK's HTTP client caches GET responses.
+		mock := &test.ClientMock{
+			ListUsersPageFunc: func(_ context.Context, _ *client.ReportFilters, _ string, _ ...string) ([]*client.User, string, *v2.RateLimitDescription, error) {
+				users := make([]*client.User, 0, len(employees))
+				for _, id := range employees {
+					users = append(users, &client.User{Id: id, Status: client.UserStatusActive})
+				}
+				return users, "", nil, nil
+			},
+			ListEmployeeTableRowsFunc: func(_ context.Context, employeeId string, _ string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+				return []client.TableRow{{"employeeId": employeeId, "customCourse": "security"}}, nil, nil
+			},
+		}
+		pool, err := client.NewPool(2)
+		require.Nil(t, err)
+		mapping := &CustomTableMapping{Table: "customTraining", ResourceTypeId: "training", KeyColumn: "customCourse", PerEmployee: true}
+		require.Nil(t, mapping.validate())
+		builder := customTableBuilder(newDirectory(mock, pool, userConfig{}, nil), mapping)
+
+		_, _, _, err = builder.List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		for i := 0; i < 2; i++ {
+			require.ElementsMatch(t, employees, principals(t, builder))
+		}
+		require.Len(t, mock.ListEmployeeTableRowsCalls(), len(employees))
+		require.Len(t, mock.ListUsersPageCalls(), 1)
+
+		builder.directory.reset()
+		require.ElementsMatch(t, employees, principals(t, builder))
+		require.Len(t, mock.ListEmployeeTableRowsCalls(), 2*len(employees))
+	})
+
+	t.Run("should skip employees whose rows cannot be read", func(t *testing.T) {
+		server.InjectFailure(test.Failure{Path: "/employees/" + grace + "/tables", Status: http.StatusForbidden, Times: 1})
+		require.ElementsMatch(t, []string{ada, alan}, principals(t, newBuilder(t)))
+	})
+
+	t.Run("should retry rate limited employees", func(t *testing.T) {
+		server.InjectFailure(test.Failure{Path: "/employees/" + alan + "/tables", Status: http.StatusServiceUnavailable, RetryAfter: "0", Times: 2})
+		require.ElementsMatch(t, employees, principals(t, newBuilder(t)))
+	})
+
+	t.Run("should fail the page when still rate limited", func(t *testing.T) {
+		server.InjectFailure(test.Failure{Path: "/employees/" + ada + "/tables", Status: http.StatusServiceUnavailable, RetryAfter: "0", Times: 3})
+		_, _, _, err := newBuilder(t).Grants(ctx, security, &pagination.Token{})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
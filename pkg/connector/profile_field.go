@@ -0,0 +1,117 @@
+package connector
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Profile field transforms.
+const (
+	// ProfileTransformHash replaces a value with its HMAC-SHA256 under the
+	// tenant's profile hash salt, so it can be joined on without being read.
+	ProfileTransformHash = "hash"
+	// ProfileTransformTruncate keeps the first N characters of a value.
+	ProfileTransformTruncate = "truncate"
+	// ProfileTransformLowercase lower-cases a value.
+	ProfileTransformLowercase = "lowercase"
+	// ProfileTransformDomain keeps only the domain of an email address.
+	ProfileTransformDomain = "domain"
+)
+
+// ProfileField is a parsed --profile-fields entry: an employee field copied
+// into user profiles, and the transforms applied to its value, in order:
+//
+//	workEmail:lowercase:hash
+//	jobTitle:truncate=16
+type ProfileField struct {
+	Name       string
+	Transforms []ProfileTransform
+}
+
+// ProfileTransform is one transform of a ProfileField. Length is only set for
+// ProfileTransformTruncate.
+type ProfileTransform struct {
+	Kind   string
+	Length int
+}
+
+// ParseProfileField parses a field name followed by colon-separated
+// transforms.
+func ParseProfileField(entry string) (*ProfileField, error) {
+	parts := strings.Split(entry, ":")
+	name := strings.TrimSpace(parts[0])
+	if name == "" {
+		return nil, fmt.Errorf("bamboohr-connector: profile field names must not be empty")
+	}
+
+	rv := &ProfileField{Name: name}
+	for _, part := range parts[1:] {
+		kind, argument, hasArgument := strings.Cut(strings.TrimSpace(part), "=")
+		transform := ProfileTransform{Kind: kind}
+		switch kind {
+		case ProfileTransformHash, ProfileTransformLowercase, ProfileTransformDomain:
+			if hasArgument {
+				return nil, fmt.Errorf("bamboohr-connector: profile field %q: %s takes no argument", name, kind)
+			}
+		case ProfileTransformTruncate:
+			length, err := strconv.Atoi(argument)
+			if err != nil || length <= 0 {
+				return nil, fmt.Errorf("bamboohr-connector: profile field %q: expected truncate=<length>, got %q", name, part)
+			}
+			transform.Length = length
+		default:
+			return nil, fmt.Errorf(
+				"bamboohr-connector: profile field %q: unknown transform %q, expected hash, truncate=<length>, lowercase or domain",
+				name,
+				kind,
+			)
+		}
+		rv.Transforms = append(rv.Transforms, transform)
+	}
+	return rv, nil
+}
+
+// hashed reports whether any of the field's transforms needs the salt.
+func (f *ProfileField) hashed() bool {
+	for _, transform := range f.Transforms {
+		if transform.Kind == ProfileTransformHash {
+			return true
+		}
+	}
+	return false
+}
+
+// apply runs the field's transforms over a value. Empty values stay empty, so
+// a hash does not make missing data look present.
+func (f *ProfileField) apply(value string, salt []byte) string {
+	for _, transform := range f.Transforms {
+		if value == "" {
+			return value
+		}
+		switch transform.Kind {
+		case ProfileTransformHash:
+			mac := hmac.New(sha256.New, salt)
+			mac.Write([]byte(value))
+			value = hex.EncodeToString(mac.Sum(nil))
+		case ProfileTransformTruncate:
+			runes := []rune(value)
+			if len(runes) > transform.Length {
+				value = string(runes[:transform.Length])
+			}
+		case ProfileTransformLowercase:
+			value = strings.ToLower(value)
+		case ProfileTransformDomain:
+			at := strings.LastIndex(value, "@")
+			if at < 0 {
+				value = ""
+				continue
+			}
+			value = strings.ToLower(value[at+1:])
+		}
+	}
+	return value
+}
@@ -0,0 +1,109 @@
+package connector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileFieldTransforms(t *testing.T) {
+	salt := []byte("tenant-salt")
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte("ada@example.com"))
+	adaHash := hex.EncodeToString(mac.Sum(nil))
+
+	testCases := []struct {
+		entry    string
+		value    string
+		expected string
+	}{
+		{"workEmail", "Ada@Example.com", "Ada@Example.com"},
+		{"workEmail:lowercase", "Ada@Example.com", "ada@example.com"},
+		{"workEmail:domain", "Ada@Example.com", "example.com"},
+		{"workEmail:domain", "not an email", ""},
+		{"workEmail:lowercase:hash", "Ada@Example.com", adaHash},
+		{"workEmail:hash", "", ""},
+		{"jobTitle:truncate=8", "Principal Engineer", "Principa"},
+		{"jobTitle:truncate=8", "CTO", "CTO"},
+		{"jobTitle: lowercase : truncate=5", "Ingénieur", "ingén"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.entry, func(t *testing.T) {
+			field, err := ParseProfileField(testCase.entry)
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, field.apply(testCase.value, salt))
+		})
+	}
+}
+
+func TestProfileFieldParseErrors(t *testing.T) {
+	for _, entry := range []string{
+		"",
+		":hash",
+		"workEmail:",
+		"workEmail:encrypt",
+		"workEmail:hash=sha1",
+		"jobTitle:truncate",
+		"jobTitle:truncate=0",
+		"jobTitle:truncate=ten",
+	} {
+		t.Run(entry, func(t *testing.T) {
+			_, err := ParseProfileField(entry)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestProfileFields(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.AddEmployee(map[string]string{
+		"firstName":      "Ada",
+		"lastName":       "Lovelace",
+		"workEmail":      "Ada@Example.com",
+		"employeeNumber": "E-100",
+	})
+
+	t.Run("should require a salt to hash", func(t *testing.T) {
+		_, err := New(ctx, "mock-company", "mock-access-token", WithProfileFields([]string{"employeeNumber:hash"}))
+		require.ErrorContains(t, err, "requires a profile hash salt")
+	})
+
+	t.Run("should reject duplicate fields", func(t *testing.T) {
+		_, err := New(ctx, "mock-company", "mock-access-token", WithProfileFields([]string{"workEmail", "workEmail:domain"}))
+		require.ErrorContains(t, err, "listed more than once")
+	})
+
+	t.Run("should transform profile values", func(t *testing.T) {
+		connector, err := New(
+			ctx,
+			"mock-company",
+			"mock-access-token",
+			WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)),
+			WithProfileFields([]string{"workEmail:domain", "employeeNumber:hash"}),
+			WithProfileHashSalt("tenant-salt"),
+		)
+		require.Nil(t, err)
+
+		resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, resources, 1)
+		userTrait, err := resource.GetUserTrait(resources[0])
+		require.Nil(t, err)
+
+		profile := userTrait.Profile.AsMap()
+		require.Equal(t, "example.com", profile["workEmail"])
+		require.Len(t, profile["employeeNumber"], sha256.Size*2)
+		require.NotContains(t, profile["employeeNumber"], "E-100")
+	})
+}
@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const sensitiveBody = `{"employees":[{"id":"1","firstName":"Ada","ssn":"123-45-6789","pay":{"salary":120000},"address1":"12 Main St"}]}`
+
+func TestRedactBody(t *testing.T) {
+	redacted := client.RedactBody([]byte(sensitiveBody))
+	require.NotContains(t, redacted, "123-45-6789")
+	require.NotContains(t, redacted, "120000")
+	require.NotContains(t, redacted, "Main St")
+	require.Contains(t, redacted, `"firstName":"Ada"`)
+
+	t.Run("should truncate long bodies", func(t *testing.T) {
+		redacted := client.RedactBody([]byte(`"` + strings.Repeat("x", client.MaxRedactedBodyLength+8) + `"`))
+		require.True(t, strings.HasSuffix(redacted, "... (10 more bytes)"))
+	})
+
+	t.Run("should mask bodies that are not JSON", func(t *testing.T) {
+		redacted := client.RedactBody([]byte("<html><body>Ada Lovelace, 123-45-6789</body></html>"))
+		require.NotContains(t, redacted, "Ada")
+		require.True(t, strings.HasPrefix(redacted, client.Redacted))
+	})
+
+	t.Run("should mask every denied field", func(t *testing.T) {
+		for _, field := range client.DeniedFields {
+			redacted := client.RedactBody([]byte(`{"` + field + `":"secret value"}`))
+			require.NotContains(t, redacted, "secret value", field)
+		}
+	})
+}
+
+func TestRedactedRequests(t *testing.T) {
+	server := test.NewFakeServer()
+	defer server.Close()
+	server.APIKey = "mock-access-token"
+	server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace", "ssn": "123-45-6789"})
+
+	t.Run("should redact error bodies", func(t *testing.T) {
+		server.InjectFailure(test.Failure{
+			Path:   client.UsersListUrlPath,
+			Status: http.StatusBadRequest,
+			Body:   sensitiveBody,
+			Times:  1,
+		})
+		_, _, err := fakeServerClient(t, server, client.DataSourceCustomReport).ListUsers(context.Background())
+		require.Error(t, err)
+		require.NotContains(t, err.Error(), "123-45-6789")
+	})
+
+	t.Run("should only log redacted bodies when enabled", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		ctx := ctxzap.ToContext(context.Background(), zap.New(core))
+
+		for _, opts := range [][]client.Option{nil, {client.WithBodyLogging()}} {
+			bambooHRClient, err := client.New(ctx, "mock-access-token", "mock-company", opts...)
+			require.Nil(t, err)
+			bambooHRClient.SetBaseUrl(server.URL)
+			bambooHRClient.AllowSensitiveFields()
+			_, _, err = bambooHRClient.ListUsers(ctx, "ssn")
+			require.Nil(t, err)
+
+			if opts == nil {
+				require.Zero(t, logs.FilterMessage("BambooHR response").Len())
+			}
+		}
+
+		responses := logs.FilterMessage("BambooHR response").All()
+		require.Len(t, responses, 1)
+		for _, entry := range logs.All() {
+			for _, field := range entry.Context {
+				require.NotContains(t, field.String, "123-45-6789")
+				require.NotContains(t, field.String, "mock-access-token")
+			}
+		}
+		require.Contains(t, responses[0].ContextMap()["http.body"], client.Redacted)
+		headers := logs.FilterMessage("BambooHR request").All()[0].ContextMap()["http.headers"]
+		require.Equal(t, client.Redacted, headers.(map[string]string)["Authorization"])
+	})
+}
@@ -21,4 +21,53 @@ var (
 		},
 		Annotations: annotationsForUserResourceType(),
 	}
+	resourceTypeApp = &v2.ResourceType{
+		Id:          "app",
+		DisplayName: "App",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_APP,
+		},
+	}
+	resourceTypeAsset = &v2.ResourceType{
+		Id:          "asset",
+		DisplayName: "Asset",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_GROUP,
+		},
+	}
+	resourceTypeBenefitPlan = &v2.ResourceType{
+		Id:          "benefit_plan",
+		DisplayName: "Benefit Plan",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_GROUP,
+		},
+	}
+	resourceTypeManagerRole = &v2.ResourceType{
+		Id:          "manager_role",
+		DisplayName: "Manager Role",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_ROLE,
+		},
+	}
+	resourceTypeEmployment = &v2.ResourceType{
+		Id:          "employment",
+		DisplayName: "Employment",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_GROUP,
+		},
+	}
 )
+
+// builtInResourceTypes lists the resource types the connector syncs without
+// configuration, or behind an option, so that configured resource types can
+// be checked against them.
+func builtInResourceTypes() []*v2.ResourceType {
+	return []*v2.ResourceType{
+		resourceTypeUser,
+		resourceTypeApp,
+		resourceTypeAsset,
+		resourceTypeBenefitPlan,
+		resourceTypeManagerRole,
+		resourceTypeEmployment,
+	}
+}
@@ -0,0 +1,151 @@
+package connector
+
+import (
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+const fullSyncGoldenFile = "../../test/fixtures/full_sync.golden.json"
+
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// seedFakeServer fills a fake server with a small company that exercises
+// every resource type.
+func seedFakeServer(server *test.FakeServer) {
+	server.SetCompany(&client.CompanyInformation{LegalName: "Acme Inc.", DisplayName: "Acme"}, pngHeader)
+
+	ada := server.AddEmployee(map[string]string{
+		"firstName":               "Ada",
+		"lastName":                "Lovelace",
+		"workEmail":               "ada@example.com",
+		"hireDate":                "2019-04-01",
+		"employmentHistoryStatus": "Full-Time",
+		"department":              "Engineering",
+	})
+	server.SetEmployeePhoto(ada, pngHeader)
+	grace := server.AddEmployee(map[string]string{
+		"firstName":               "Grace",
+		"lastName":                "Hopper",
+		"workEmail":               "grace@example.com",
+		"hireDate":                "2020-06-15",
+		"employmentHistoryStatus": "Contractor",
+		"department":              "Engineering",
+		"supervisor":              "Ada Lovelace",
+		"supervisorEId":           ada,
+		"supervisorId":            ada,
+		"supervisorEmail":         "ada@example.com",
+	})
+	server.AddEmployee(map[string]string{
+		"firstName":       "Alan",
+		"lastName":        "Turing",
+		"workEmail":       "alan@example.com",
+		"status":          "Inactive",
+		"hireDate":        "2018-01-08",
+		"terminationDate": "2023-01-31",
+		"department":      "Research",
+	})
+
+	server.AddLoginUser(&client.LoginUser{
+		EmployeeId: client.FlexibleString(ada),
+		FirstName:  "Ada",
+		LastName:   "Lovelace",
+		Email:      "ada@example.com",
+		Status:     "enabled",
+		LastLogin:  "2024-01-02T03:04:05+00:00",
+	})
+	server.AddLoginUser(&client.LoginUser{
+		FirstName: "Payroll",
+		LastName:  "Admin",
+		Email:     "payroll@example.com",
+		Status:    "enabled",
+	})
+
+	server.AddCompanyBenefit(&client.CompanyBenefit{
+		Id:                   "10",
+		CompanyBenefitTypeId: "1",
+		BenefitName:          "Medical",
+		StartDate:            "2020-01-01",
+	})
+	server.AddEmployeeBenefit(&client.EmployeeBenefit{
+		EmployeeId:        ada,
+		CompanyBenefitId:  "10",
+		CoverageStartDate: "2020-01-01",
+		EnrollmentStatus:  "Enrolled",
+	})
+
+	server.AddTableRow(assetsTable, ada, client.TableRow{
+		"assetCategory":     "Computer",
+		"assetDescription":  "MacBook Pro",
+		"assetSerialNumber": "C02ADA",
+		"assetDateAssigned": "2023-01-01",
+	})
+	server.AddTableRow("customSystemAccess", grace, client.TableRow{
+		"customSystem":     "github",
+		"customSystemName": "GitHub",
+	})
+
+	server.AddListField(&client.ListField{
+		FieldId: "4",
+		Alias:   "department",
+		Name:    "Department",
+		Options: []*client.ListOption{
+			{Id: "1", Name: "Engineering", Archived: "no"},
+			{Id: "2", Name: "Research", Archived: "no"},
+		},
+	})
+}
+
+func TestFullSyncGolden(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	seedFakeServer(server)
+
+	bambooHRClient, err := client.New(ctx, "mock-access-token", "mock-company")
+	require.Nil(t, err)
+	bambooHRClient.SetBaseUrl(server.URL)
+
+	bambooHR, err := New(
+		ctx,
+		"mock-company",
+		"mock-access-token",
+		WithClient(bambooHRClient),
+		WithCustomTables([]*CustomTableMapping{
+			{
+				Table:          "customSystemAccess",
+				ResourceTypeId: "system_access",
+				DisplayName:    "System Access",
+				Trait:          customTableTraitRole,
+				KeyColumn:      "customSystem",
+				DisplayColumn:  "customSystemName",
+				EmployeeColumn: customTableDefaultEmployeeCol,
+				Entitlement:    customTableDefaultEntitlement,
+			},
+		}),
+		WithGroupByFields([]string{"Department"}),
+	)
+	require.Nil(t, err)
+
+	connector, err := connectorbuilder.NewConnector(ctx, bambooHR)
+	require.Nil(t, err)
+
+	test.AssertGolden(t, fullSyncGoldenFile, test.SyncSnapshot(ctx, t, connector), *update)
+
+	t.Run("should read shared data once per sync", func(t *testing.T) {
+		requests := make(map[string]int)
+		for _, request := range server.Requests() {
+			requests[request]++
+		}
+		require.Equal(t, 1, requests["POST /api/gateway.php/mock-company/v1/reports/custom"])
+		require.Equal(t, 1, requests["GET /api/gateway.php/mock-company/v1/meta/lists"])
+	})
+}
@@ -3,6 +3,8 @@ package connector
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -11,9 +13,115 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 )
 
+const (
+	employeePhotoAssetPrefix = "employee_photo:"
+	userStatusPendingStart   = "pending start"
+)
+
+// userConfig holds the options that shape how employees become users.
+type userConfig struct {
+	// accountTypes maps lower-cased employment statuses to account types.
+	// Employees with any other status are human accounts.
+	accountTypes map[string]v2.UserTrait_AccountType
+	// filter, when set, limits which employees are synced.
+	filter *UserFilter
+	// excludeTerminated drops inactive employees entirely. Otherwise they
+	// are kept for terminatedRetentionDays after their termination date, or
+	// indefinitely when that is zero.
+	excludeTerminated       bool
+	terminatedRetentionDays int
+	// terminatedStatus is the status synced inactive employees get. When it
+	// is unspecified or deleted, those with a termination date are deleted
+	// and the rest disabled.
+	terminatedStatus v2.UserTrait_Status_Status
+	// excludeFutureHires drops employees whose hire date is in the future.
+	excludeFutureHires bool
+	// pendingJobChanges adds the next future-dated jobInfo and
+	// employmentStatus changes to the profile.
+	pendingJobChanges bool
+	// employmentHistory adds every jobInfo and employmentStatus row, and
+	// the hire dates they imply, to the profile.
+	employmentHistory bool
+	// orgChartReport, when set, is the path org chart issues are written to.
+	orgChartReport string
+	// profileFields are extra employee fields copied into the user profile,
+	// and profileHashSalt is the tenant secret their hash transforms use.
+	profileFields   []*ProfileField
+	profileHashSalt []byte
+}
+
+// profileFieldNames returns the names of the extra profile fields.
+func (c userConfig) profileFieldNames() []string {
+	rv := make([]string, 0, len(c.profileFields))
+	for _, field := range c.profileFields {
+		rv = append(rv, field.Name)
+	}
+	return rv
+}
+
+// includes reports whether an employee should be synced.
+func (c userConfig) includes(user *client.User, now time.Time) bool {
+	if c.filter != nil && !c.filter.Match(user.Fields) {
+		return false
+	}
+	if c.excludeFutureHires && isFutureHire(user, now) {
+		return false
+	}
+	if user.IsActive() {
+		return true
+	}
+	if c.excludeTerminated {
+		return false
+	}
+	if c.terminatedRetentionDays <= 0 {
+		return true
+	}
+	// Employees without a termination date cannot age out of the window.
+	terminationDate, ok := client.ParseDate(user.TerminationDate)
+	if !ok {
+		return true
+	}
+	return now.Before(terminationDate.AddDate(0, 0, c.terminatedRetentionDays))
+}
+
+// isFutureHire reports whether an employee's hire date has not yet arrived.
+func isFutureHire(user *client.User, now time.Time) bool {
+	hireDate, ok := client.ParseDate(user.HireDate)
+	return ok && hireDate.After(now)
+}
+
+// userStatus returns the trait status for an employee. Future hires are
+// enabled so they can be provisioned before their first day, but are marked
+// as pending start. By default only inactive employees with a termination
+// date have left the company and are deleted; other inactive employees are
+// disabled. terminatedStatus can disable or enable every inactive employee
+// instead.
+func (c userConfig) userStatus(user *client.User, now time.Time) (v2.UserTrait_Status_Status, string) {
+	if user.IsActive() && isFutureHire(user, now) {
+		return v2.UserTrait_Status_STATUS_ENABLED, userStatusPendingStart
+	}
+	if user.IsActive() || c.terminatedStatus == v2.UserTrait_Status_STATUS_ENABLED {
+		return v2.UserTrait_Status_STATUS_ENABLED, ""
+	}
+	if _, ok := client.ParseDate(user.TerminationDate); ok && c.terminatedStatus != v2.UserTrait_Status_STATUS_DISABLED {
+		return v2.UserTrait_Status_STATUS_DELETED, "terminated " + user.TerminationDate
+	}
+	return v2.UserTrait_Status_STATUS_DISABLED, user.Status
+}
+
+// accountType returns the account type for an employment status.
+func (c userConfig) accountType(employmentStatus string) v2.UserTrait_AccountType {
+	if accountType, ok := c.accountTypes[strings.ToLower(employmentStatus)]; ok {
+		return accountType
+	}
+	return v2.UserTrait_ACCOUNT_TYPE_HUMAN
+}
+
 type UserResourceType struct {
 	resourceType   *v2.ResourceType
-	bambooHRClient *client.BambooHRClient
+	bambooHRClient client.Client
+	config         userConfig
+	directory      *directory
 }
 
 func (o *UserResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -25,6 +133,10 @@ func WithRateLimitAnnotations(
 ) annotations.Annotations {
 	outputAnnotations := annotations.Annotations{}
 	for _, annotation := range ratelimitDescriptionAnnotations {
+		// Requests served from the directory have no rate limit data.
+		if annotation == nil {
+			continue
+		}
 		outputAnnotations.Append(annotation)
 	}
 
@@ -33,25 +145,65 @@ func WithRateLimitAnnotations(
 
 func (o *UserResourceType) List(
 	ctx context.Context,
-	_ *v2.ResourceId,
+	parentResourceID *v2.ResourceId,
 	pt *pagination.Token,
 ) ([]*v2.Resource, string, annotations.Annotations, error) {
-	users, ratelimitData, err := o.bambooHRClient.ListUsers(ctx)
+	if listedAsAppChild(parentResourceID) {
+		return nil, "", nil, nil
+	}
+
+	// The org chart needs every employee, so the whole report is read up
+	// front, and the users are returned a report page at a time.
+	employees, ratelimitData, err := o.directory.listEmployees(ctx)
 	outputAnnotations := WithRateLimitAnnotations(ratelimitData)
 	if err != nil {
 		return nil, "", outputAnnotations, err
 	}
+	page, err := employees.page(pt.Token)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+	if pt.Token == "" {
+		err = employees.org.validate(ctx, employees.included, o.config.orgChartReport)
+		if err != nil {
+			return nil, "", outputAnnotations, err
+		}
+	}
+
+	lastLogins, loginsRatelimitData, err := readOnce(ctx, o.directory, "lastLogins", o.lastLogins)
+	outputAnnotations = WithRateLimitAnnotations(ratelimitData, loginsRatelimitData)
+	if err != nil {
+		return nil, "", outputAnnotations, err
+	}
+
+	// The jobInfo and employmentStatus tables are only read for the options
+	// that need them.
+	var history *jobHistory
+	if o.config.pendingJobChanges || o.config.employmentHistory {
+		var historyRatelimitData *v2.RateLimitDescription
+		history, historyRatelimitData, err = readOnce(ctx, o.directory, "jobHistory", func(ctx context.Context) (*jobHistory, *v2.RateLimitDescription, error) {
+			return listJobHistory(ctx, o.bambooHRClient)
+		})
+		outputAnnotations = WithRateLimitAnnotations(ratelimitData, loginsRatelimitData, historyRatelimitData)
+		if err != nil {
+			return nil, "", outputAnnotations, err
+		}
+	}
 
-	rv := make([]*v2.Resource, 0)
-	for _, user := range users {
-		newResource, err := userResource(ctx, user)
+	now := time.Now()
+	rv := make([]*v2.Resource, 0, len(page.users))
+	for _, user := range page.users {
+		if !employees.includes(user.Id) {
+			continue
+		}
+		newResource, err := userResource(ctx, user, o.config, employees.org.position(user.Id), history, lastLogins[user.Id], now)
 		if err != nil {
 			return nil, "", nil, err
 		}
 		rv = append(rv, newResource)
 	}
 
-	return rv, "", outputAnnotations, nil
+	return rv, page.next, outputAnnotations, nil
 }
 
 func (o *UserResourceType) Entitlements(
@@ -80,19 +232,66 @@ func (o *UserResourceType) Grants(
 	return nil, "", nil, nil
 }
 
-func userBuilder(bambooHRClient *client.BambooHRClient) *UserResourceType {
+// lastLogins returns the last login time of each employee that has a
+// BambooHR account.
+func (o *UserResourceType) lastLogins(ctx context.Context) (map[string]time.Time, *v2.RateLimitDescription, error) {
+	loginUsers, ratelimitData, err := o.directory.loginUsers(ctx)
+	if err != nil {
+		return nil, ratelimitData, err
+	}
+
+	rv := make(map[string]time.Time)
+	for _, loginUser := range loginUsers {
+		lastLogin, ok := loginUser.LastLoginTime()
+		if !ok || loginUser.EmployeeId == "" {
+			continue
+		}
+		if lastLogin.After(rv[loginUser.EmployeeId.String()]) {
+			rv[loginUser.EmployeeId.String()] = lastLogin
+		}
+	}
+	return rv, ratelimitData, nil
+}
+
+func userBuilder(directory *directory) *UserResourceType {
 	return &UserResourceType{
 		resourceType:   resourceTypeUser,
-		bambooHRClient: bambooHRClient,
+		bambooHRClient: directory.bambooHRClient,
+		config:         directory.config,
+		directory:      directory,
 	}
 }
 
-// userResource convert a BambooHR into a Resource.
+// userResource convert a BambooHR into a Resource. lastLogin is the zero time
+// when the employee has never logged in to BambooHR. Job changes in history
+// that take effect after now are added to the profile as pending attributes.
 func userResource(
 	ctx context.Context,
 	user *client.User,
+	config userConfig,
+	position *orgPosition,
+	history *jobHistory,
+	lastLogin time.Time,
+	now time.Time,
 ) (*v2.Resource, error) {
-	profile := userProfile(ctx, user)
+	profile := userProfile(ctx, user, config, position)
+	if isFutureHire(user, now) {
+		profile["startDate"] = user.HireDate
+	}
+	if config.pendingJobChanges {
+		for attribute, value := range history.pending(user.Id, now) {
+			profile[attribute] = value
+		}
+	}
+	if config.employmentHistory {
+		hireDates := make([]interface{}, 0)
+		for _, hireDate := range history.hireDates(user.Id) {
+			hireDates = append(hireDates, hireDate)
+		}
+		profile["employmentHistory"] = history.employmentHistory(user.Id)
+		profile["hireDates"] = hireDates
+		profile["rehired"] = len(hireDates) > 1
+	}
 	displayName := fmt.Sprintf(
 		"%s %s",
 		user.FirstName,
@@ -101,6 +300,20 @@ func userResource(
 	userTraitOptions := []resource.UserTraitOption{
 		resource.WithUserProfile(profile),
 		resource.WithEmail(user.Email, true),
+		resource.WithAccountType(config.accountType(user.EmploymentStatus)),
+		resource.WithDetailedStatus(config.userStatus(user, now)),
+	}
+	if hireDate, ok := client.ParseDate(user.HireDate); ok {
+		userTraitOptions = append(userTraitOptions, resource.WithCreatedAt(hireDate))
+	}
+	if !lastLogin.IsZero() {
+		userTraitOptions = append(userTraitOptions, resource.WithLastLogin(lastLogin))
+	}
+	if user.HasPhoto() {
+		userTraitOptions = append(
+			userTraitOptions,
+			resource.WithUserIcon(&v2.AssetRef{Id: employeePhotoAssetId(user.Id)}),
+		)
 	}
 
 	return resource.NewUserResource(
@@ -111,13 +324,31 @@ func userResource(
 	)
 }
 
-func userProfile(ctx context.Context, user *client.User) map[string]interface{} {
+func employeePhotoAssetId(employeeId string) string {
+	return employeePhotoAssetPrefix + employeeId
+}
+
+// userProfile builds a user's profile, including their position in the org
+// chart and the extra profile fields with their transforms applied.
+func userProfile(ctx context.Context, user *client.User, config userConfig, position *orgPosition) map[string]interface{} {
 	profile := make(map[string]interface{})
 	profile["supervisorEId"] = user.SupervisorEId
 	profile["supervisorFullName"] = user.Supervisor
 	profile["supervisorId"] = user.SupervisorId
 	profile["supervisorEmail"] = user.SupervisorEmail
 	profile["user_id"] = user.Id
+	managerChain := make([]interface{}, 0, len(position.managerChain))
+	for _, manager := range position.managerChain {
+		managerChain = append(managerChain, manager)
+	}
+	profile["managerChain"] = managerChain
+	profile["orgDepth"] = len(position.managerChain)
+	profile["topLevelManager"] = position.topLevelManager
+	for _, field := range config.profileFields {
+		if value, ok := user.Fields[field.Name]; ok {
+			profile[field.Name] = field.apply(value, config.profileHashSalt)
+		}
+	}
 
 	return profile
 }
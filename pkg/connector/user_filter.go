@@ -0,0 +1,293 @@
+package connector
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+)
+
+const lastChangedField = "lastChanged"
+
+// UserFilter is a parsed --user-filter expression. Expressions compare report
+// fields against literals and combine the comparisons with &&, || and !:
+//
+//	status == "Active" && (location != "Test" || department == "IT")
+//
+// Supported comparison operators are ==, !=, <, <=, > and >=; ordering
+// comparisons are lexical, which suits BambooHR's ISO 8601 dates.
+type UserFilter struct {
+	root   filterNode
+	fields []string
+}
+
+type filterNode interface {
+	match(fields map[string]string) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+
+func (n *filterAnd) match(fields map[string]string) bool {
+	return n.left.match(fields) && n.right.match(fields)
+}
+
+type filterOr struct{ left, right filterNode }
+
+func (n *filterOr) match(fields map[string]string) bool {
+	return n.left.match(fields) || n.right.match(fields)
+}
+
+type filterNot struct{ node filterNode }
+
+func (n *filterNot) match(fields map[string]string) bool {
+	return !n.node.match(fields)
+}
+
+type filterComparison struct {
+	field    string
+	operator string
+	value    string
+}
+
+func (n *filterComparison) match(fields map[string]string) bool {
+	actual := fields[n.field]
+	switch n.operator {
+	case "==":
+		return actual == n.value
+	case "!=":
+		return actual != n.value
+	case "<":
+		return actual < n.value
+	case "<=":
+		return actual <= n.value
+	case ">":
+		return actual > n.value
+	case ">=":
+		return actual >= n.value
+	default:
+		return false
+	}
+}
+
+// ParseUserFilter parses a filter expression.
+func ParseUserFilter(expression string) (*UserFilter, error) {
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("bamboohr-connector: unexpected %q in user filter", p.tokens[p.pos].text)
+	}
+	return &UserFilter{root: root, fields: p.fields}, nil
+}
+
+// Match reports whether an employee's report fields satisfy the filter.
+func (f *UserFilter) Match(fields map[string]string) bool {
+	return f.root.match(fields)
+}
+
+// Fields returns the report fields the filter reads, so that they can be
+// requested alongside the default ones.
+func (f *UserFilter) Fields() []string {
+	return f.fields
+}
+
+// ReportFilters returns the part of the filter BambooHR can apply itself. A
+// lower bound on lastChanged that must always hold, i.e. one that is not
+// under an || or !, is sent as the report's lastChanged filter. The whole
+// expression is still evaluated locally.
+func (f *UserFilter) ReportFilters() *client.ReportFilters {
+	since := lastChangedLowerBound(f.root)
+	if since == "" {
+		return nil
+	}
+	return &client.ReportFilters{
+		LastChanged: &client.ReportLastChangedFilter{
+			IncludeNull: "no",
+			Value:       since,
+		},
+	}
+}
+
+func lastChangedLowerBound(node filterNode) string {
+	switch n := node.(type) {
+	case *filterAnd:
+		left, right := lastChangedLowerBound(n.left), lastChangedLowerBound(n.right)
+		if left > right {
+			return left
+		}
+		return right
+	case *filterComparison:
+		if n.field == lastChangedField && (n.operator == ">=" || n.operator == ">") {
+			return n.value
+		}
+	}
+	return ""
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenIdent filterTokenKind = iota
+	filterTokenLiteral
+	filterTokenOperator
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	tokens := make([]filterToken, 0)
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("bamboohr-connector: unterminated string in user filter")
+			}
+			value, err := strconv.Unquote(string(runes[i : end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("bamboohr-connector: invalid string in user filter: %w", err)
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenLiteral, text: value})
+			i = end + 1
+		case strings.ContainsRune("=!<>&|()", r):
+			text := string(r)
+			if i+1 < len(runes) && slices.Contains([]string{"==", "!=", "<=", ">=", "&&", "||"}, string(runes[i:i+2])) {
+				text = string(runes[i : i+2])
+			}
+			if text == "=" || text == "&" || text == "|" {
+				return nil, fmt.Errorf("bamboohr-connector: unexpected %q in user filter", text)
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: text})
+			i += len(text)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || strings.ContainsRune("_.-", runes[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenIdent, text: string(runes[i:end])})
+			i = end
+		default:
+			return nil, fmt.Errorf("bamboohr-connector: unexpected %q in user filter", string(r))
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	fields []string
+}
+
+func (p *filterParser) peekOperator(operator string) bool {
+	return p.pos < len(p.tokens) &&
+		p.tokens[p.pos].kind == filterTokenOperator &&
+		p.tokens[p.pos].text == operator
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.peekOperator("!") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{node: node}, nil
+	}
+	if p.peekOperator("(") {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekOperator(")") {
+			return nil, fmt.Errorf("bamboohr-connector: missing ) in user filter")
+		}
+		p.pos++
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	if p.pos+3 > len(p.tokens) {
+		return nil, fmt.Errorf("bamboohr-connector: incomplete comparison in user filter")
+	}
+	field, operator, value := p.tokens[p.pos], p.tokens[p.pos+1], p.tokens[p.pos+2]
+	if field.kind != filterTokenIdent {
+		return nil, fmt.Errorf("bamboohr-connector: expected a field name in user filter, got %q", field.text)
+	}
+	if operator.kind != filterTokenOperator ||
+		!slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, operator.text) {
+		return nil, fmt.Errorf("bamboohr-connector: expected a comparison after %q in user filter", field.text)
+	}
+	// Bare words on the right-hand side are taken literally, so that numbers
+	// do not need quoting.
+	if value.kind == filterTokenOperator {
+		return nil, fmt.Errorf("bamboohr-connector: expected a value after %q in user filter", operator.text)
+	}
+	p.pos += 3
+
+	if !slices.Contains(p.fields, field.text) {
+		p.fields = append(p.fields, field.text)
+	}
+	return &filterComparison{
+		field:    field.text,
+		operator: operator.text,
+		value:    value.text,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-bamboohr/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserFilterMatch(t *testing.T) {
+	fields := map[string]string{
+		"status":      "Active",
+		"location":    "Lindon",
+		"department":  "IT",
+		"lastChanged": "2024-03-01T00:00:00+00:00",
+	}
+
+	testCases := []struct {
+		expression string
+		expected   bool
+	}{
+		{`status == "Active"`, true},
+		{`status != "Active"`, false},
+		{`status == "Active" && location != "Test"`, true},
+		{`status == "Active" && location == "Test"`, false},
+		{`location == "Test" || department == "IT"`, true},
+		{`!(location == "Test")`, true},
+		{`status == "Active" && (location == "Test" || department == IT)`, true},
+		{`lastChanged >= "2024-01-01"`, true},
+		{`lastChanged < "2024-01-01"`, false},
+		{`missing == ""`, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.expression, func(t *testing.T) {
+			filter, err := ParseUserFilter(testCase.expression)
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, filter.Match(fields))
+		})
+	}
+}
+
+func TestUserFilterParseErrors(t *testing.T) {
+	for _, expression := range []string{
+		`status`,
+		`status = "Active"`,
+		`status == "Active`,
+		`(status == "Active"`,
+		`status == "Active" &&`,
+		`"Active" == status`,
+		`status == "Active" location == "Test"`,
+	} {
+		t.Run(expression, func(t *testing.T) {
+			_, err := ParseUserFilter(expression)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUserFilterReportFilters(t *testing.T) {
+	filter, err := ParseUserFilter(`status == "Active" && lastChanged >= "2024-01-01T00:00:00Z"`)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"status", "lastChanged"}, filter.Fields())
+	reportFilters := filter.ReportFilters()
+	require.NotNil(t, reportFilters)
+	require.Equal(t, "2024-01-01T00:00:00Z", reportFilters.LastChanged.Value)
+
+	filter, err = ParseUserFilter(`status == "Active" || lastChanged >= "2024-01-01T00:00:00Z"`)
+	require.Nil(t, err)
+	require.Nil(t, filter.ReportFilters())
+}
+
+func TestUsersListWithFilter(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	for expression, expected := range map[string]int{
+		`lastName == "lastName"`: 1,
+		`lastName == "Excluded"`: 0,
+	} {
+		connector, err := New(ctx, "mock-company", "mock-access-token", WithUserFilter(expression))
+		require.Nil(t, err)
+		setBaseUrl(t, connector, server.URL)
+
+		resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, resources, expected)
+	}
+}
+
+func TestUserFilterAppliesToEverySyncer(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	seedFakeServer(server)
+
+	snapshot := syncSnapshot(
+		ctx,
+		t,
+		fakeServerClient(t, server, client.DataSourceCustomReport),
+		WithUserFilter(`firstName != "Ada"`),
+		WithGroupByFields([]string{"Department"}),
+		WithCustomTables([]*CustomTableMapping{
+			{Table: "customSystemAccess", ResourceTypeId: "system", KeyColumn: "customSystem"},
+		}),
+	)
+
+	principals := make(map[string]bool)
+	for _, grant := range snapshot["grants"] {
+		principal := grant["principal"].(map[string]interface{})["id"].(map[string]interface{})
+		if principal["resourceType"] == resourceTypeUser.Id {
+			principals[principal["resource"].(string)] = true
+		}
+	}
+	// Ada, employee 1, has a login, a benefit, an asset and a department.
+	require.Equal(t, map[string]bool{"2": true, "3": true}, principals)
+}
@@ -2,13 +2,20 @@ package connector
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
 	"github.com/conductorone/baton-bamboohr/test"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestUsersList(t *testing.T) {
@@ -28,7 +35,7 @@ func TestUsersList(t *testing.T) {
 		}
 
 		confluenceClient.SetBaseUrl(server.URL)
-		c := userBuilder(confluenceClient)
+		c := userBuilder(newDirectory(confluenceClient, nil, userConfig{}, nil))
 
 		resources := make([]*v2.Resource, 0)
 		pToken := pagination.Token{
@@ -53,3 +60,279 @@ func TestUsersList(t *testing.T) {
 		require.NotEmpty(t, resources[0].Id)
 	})
 }
+
+func TestUserPhotos(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	connector, err := New(ctx, "mock-company", "mock-access-token")
+	require.Nil(t, err)
+	setBaseUrl(t, connector, server.URL)
+
+	resources, _, _, err := userBuilder(newDirectory(connector.client, nil, userConfig{}, nil)).List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, resources, 1)
+
+	userTrait, err := resource.GetUserTrait(resources[0])
+	require.Nil(t, err)
+	require.NotNil(t, userTrait.Icon)
+
+	t.Run("should serve employee photos", func(t *testing.T) {
+		contentType, body, err := connector.Asset(ctx, userTrait.Icon)
+		require.Nil(t, err)
+		defer body.Close()
+		require.Equal(t, "image/png", contentType)
+	})
+
+	t.Run("should not fail on missing photos", func(t *testing.T) {
+		_, body, err := connector.Asset(ctx, &v2.AssetRef{Id: employeePhotoAssetId("missing")})
+		require.Nil(t, err)
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		require.Nil(t, err)
+		require.Empty(t, data)
+	})
+
+	t.Run("should retry rate limited photos on the pool", func(t *testing.T) {
+		server := test.NewFakeServer()
+		defer server.Close()
+		id := server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace"})
+		server.SetEmployeePhoto(id, []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'})
+		server.InjectFailure(test.Failure{Path: "/employees/" + id + "/photo", Status: http.StatusTooManyRequests, RetryAfter: "0", Times: 1})
+
+		connector, err := New(ctx, "mock-company", "mock-access-token", WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)))
+		require.Nil(t, err)
+		contentType, body, err := connector.Asset(ctx, &v2.AssetRef{Id: employeePhotoAssetId(id)})
+		require.Nil(t, err)
+		defer body.Close()
+		require.Equal(t, "image/png", contentType)
+	})
+}
+
+func TestUserTrait(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	connector, err := New(
+		ctx,
+		"mock-company",
+		"mock-access-token",
+		WithAccountTypes([]string{"contractor=service"}),
+	)
+	require.Nil(t, err)
+	setBaseUrl(t, connector, server.URL)
+
+	resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+	require.Nil(t, err)
+	require.Len(t, resources, 1)
+
+	userTrait, err := resource.GetUserTrait(resources[0])
+	require.Nil(t, err)
+	require.Equal(t, v2.UserTrait_ACCOUNT_TYPE_SERVICE, userTrait.AccountType)
+	require.Equal(t, "2020-02-03", userTrait.CreatedAt.AsTime().Format(client.DateLayout))
+	require.Equal(t, "2024-01-02T03:04:05Z", userTrait.LastLogin.AsTime().Format(time.RFC3339))
+}
+
+func TestTerminatedEmployees(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	active := &client.User{Id: "1", Status: "Active"}
+	recent := &client.User{Id: "2", Status: "Inactive", TerminationDate: "2024-05-01"}
+	old := &client.User{Id: "3", Status: "Inactive", TerminationDate: "2023-01-01"}
+	undated := &client.User{Id: "4", Status: "Inactive"}
+
+	testCases := []struct {
+		name     string
+		config   userConfig
+		expected []*client.User
+	}{
+		{"default keeps everyone", userConfig{}, []*client.User{active, recent, old, undated}},
+		{"excluded", userConfig{excludeTerminated: true}, []*client.User{active}},
+		{"retention window", userConfig{terminatedRetentionDays: 90}, []*client.User{active, recent, undated}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			included := make([]*client.User, 0)
+			for _, user := range []*client.User{active, recent, old, undated} {
+				if testCase.config.includes(user, now) {
+					included = append(included, user)
+				}
+			}
+			require.Equal(t, testCase.expected, included)
+		})
+	}
+
+	statuses := func(config userConfig) []v2.UserTrait_Status_Status {
+		rv := make([]v2.UserTrait_Status_Status, 0)
+		for _, user := range []*client.User{active, recent, undated} {
+			status, _ := config.userStatus(user, now)
+			rv = append(rv, status)
+		}
+		return rv
+	}
+	enabled, disabled, deleted := v2.UserTrait_Status_STATUS_ENABLED, v2.UserTrait_Status_STATUS_DISABLED, v2.UserTrait_Status_STATUS_DELETED
+	require.Equal(t, []v2.UserTrait_Status_Status{enabled, deleted, disabled}, statuses(userConfig{}))
+	require.Equal(t, []v2.UserTrait_Status_Status{enabled, disabled, disabled}, statuses(userConfig{terminatedStatus: disabled}))
+	require.Equal(t, []v2.UserTrait_Status_Status{enabled, deleted, disabled}, statuses(userConfig{terminatedStatus: deleted}))
+	require.Equal(t, []v2.UserTrait_Status_Status{enabled, enabled, enabled}, statuses(userConfig{terminatedStatus: enabled}))
+}
+
+func TestExcludeTerminatedEmployees(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.NewFakeServer()
+	defer server.Close()
+	ada := server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace"})
+	server.AddEmployee(map[string]string{"firstName": "Alan", "lastName": "Turing", "status": "Inactive"})
+
+	for _, dataSource := range []string{client.DataSourceCustomReport, client.DataSourceDatasets} {
+		t.Run(dataSource, func(t *testing.T) {
+			bambooHRClient := fakeServerClient(t, server, dataSource)
+			users, _, err := bambooHRClient.ListFilteredUsers(ctx, &client.ReportFilters{Status: client.UserStatusActive})
+			require.Nil(t, err)
+			// Only the Datasets API filters on status.
+			if dataSource == client.DataSourceDatasets {
+				require.Len(t, users, 1)
+			} else {
+				require.Len(t, users, 2)
+			}
+
+			connector, err := New(ctx, "mock-company", "mock-access-token", WithClient(bambooHRClient), WithTerminatedEmployees(false, 0))
+			require.Nil(t, err)
+			resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+			require.Nil(t, err)
+			require.Len(t, resources, 1)
+			require.Equal(t, ada, resources[0].Id.Resource)
+		})
+	}
+
+	t.Run("should not enable terminated employees by default", func(t *testing.T) {
+		server := test.NewFakeServer()
+		defer server.Close()
+		server.AddEmployee(map[string]string{"firstName": "Ada", "lastName": "Lovelace"})
+		alan := server.AddEmployee(map[string]string{"firstName": "Alan", "lastName": "Turing", "status": "Inactive", "terminationDate": "2024-05-01"})
+		grace := server.AddEmployee(map[string]string{"firstName": "Grace", "lastName": "Hopper", "status": "Inactive"})
+
+		connector, err := New(ctx, "mock-company", "mock-access-token", WithClient(fakeServerClient(t, server, client.DataSourceCustomReport)))
+		require.Nil(t, err)
+		resources, _, _, err := userBuilder(connector.directory).List(ctx, nil, &pagination.Token{})
+		require.Nil(t, err)
+		require.Len(t, resources, 3)
+		statuses := make(map[string]v2.UserTrait_Status_Status)
+		for _, userResource := range resources {
+			userTrait, err := resource.GetUserTrait(userResource)
+			require.Nil(t, err)
+			statuses[userResource.Id.Resource] = userTrait.Status.Status
+		}
+		require.Equal(t, v2.UserTrait_Status_STATUS_DELETED, statuses[alan])
+		require.Equal(t, v2.UserTrait_Status_STATUS_DISABLED, statuses[grace])
+	})
+
+	require.ErrorContains(t, WithTerminatedStatus("archived")(&BambooHr{}), `"archived"`)
+}
+
+func TestDatasetsStatusFilter(t *testing.T) {
+	ctx := context.Background()
+
+	var request *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = io.ReadAll(r.Body)
+		writer.Header().Set("Content-Type", client.JSONContentType)
+		_, _ = writer.Write([]byte(`{"data": [], "pagination": {"current_page": 1, "total_pages": 1}}`))
+	}))
+	defer server.Close()
+
+	bambooHRClient, err := client.New(ctx, "mock-access-token", "mock-company")
+	require.Nil(t, err)
+	require.Nil(t, bambooHRClient.SetDataSource(client.DataSourceDatasets))
+	bambooHRClient.SetBaseUrl(server.URL)
+	_, _, err = bambooHRClient.ListFilteredUsers(ctx, &client.ReportFilters{Status: client.UserStatusActive})
+	require.Nil(t, err)
+
+	require.Equal(t, http.MethodPost, request.Method)
+	require.Equal(t, "/api/gateway.php/mock-company/v1/"+client.DatasetsEmployeeUrlPath, request.URL.Path)
+	require.Equal(t, "1", request.URL.Query().Get("page"))
+	query := map[string]json.RawMessage{}
+	require.Nil(t, json.Unmarshal(body, &query))
+	// The filter shape of BambooHR's "Get data from dataset" request.
+	require.JSONEq(t, `{
+		"match": "all",
+		"filters": [{"field": "status", "operator": "equal", "value": "Active"}]
+	}`, string(query["filters"]))
+}
+
+func TestFutureHires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	futureHire := &client.User{Id: "1", Status: "Active", HireDate: "2024-07-01"}
+	currentHire := &client.User{Id: "2", Status: "Active", HireDate: "2024-01-01"}
+
+	status, details := userConfig{}.userStatus(futureHire, now)
+	require.Equal(t, v2.UserTrait_Status_STATUS_ENABLED, status)
+	require.Equal(t, userStatusPendingStart, details)
+	_, details = userConfig{}.userStatus(currentHire, now)
+	require.Empty(t, details)
+
+	r, err := userResource(ctx, futureHire, userConfig{}, &orgPosition{}, nil, time.Time{}, now)
+	require.Nil(t, err)
+	userTrait, err := resource.GetUserTrait(r)
+	require.Nil(t, err)
+	require.Equal(t, "2024-07-01", userTrait.Profile.AsMap()["startDate"])
+
+	require.True(t, userConfig{}.includes(futureHire, now))
+	require.False(t, userConfig{excludeFutureHires: true}.includes(futureHire, now))
+	require.True(t, userConfig{excludeFutureHires: true}.includes(currentHire, now))
+}
+
+func TestUsersListDataSources(t *testing.T) {
+	ctx := context.Background()
+
+	server := test.FixturesServer()
+	defer server.Close()
+
+	// listUsers lists every page of users and returns them with the number
+	// of pages.
+	listUsers := func(dataSource string) ([]*v2.Resource, int) {
+		connector, err := New(ctx, "mock-company", "mock-access-token", WithDataSource(dataSource))
+		require.Nil(t, err)
+		setBaseUrl(t, connector, server.URL)
+
+		builder := userBuilder(connector.directory)
+		rv := make([]*v2.Resource, 0)
+		pages := 0
+		for token := ""; ; {
+			resources, nextToken, _, err := builder.List(ctx, nil, &pagination.Token{Token: token})
+			require.Nil(t, err)
+			rv = append(rv, resources...)
+			pages++
+			if nextToken == "" {
+				return rv, pages
+			}
+			token = nextToken
+		}
+	}
+
+	reportUsers, reportPages := listUsers(client.DataSourceCustomReport)
+	datasetUsers, datasetPages := listUsers(client.DataSourceDatasets)
+	require.Len(t, reportUsers, 1)
+	require.Len(t, datasetUsers, 2)
+	require.Equal(t, 1, reportPages)
+	require.Equal(t, 2, datasetPages)
+	require.Equal(t, reportUsers[0].Id.Resource, datasetUsers[0].Id.Resource)
+	require.Equal(t, reportUsers[0].DisplayName, datasetUsers[0].DisplayName)
+
+	reportTrait, err := resource.GetUserTrait(reportUsers[0])
+	require.Nil(t, err)
+	datasetTrait, err := resource.GetUserTrait(datasetUsers[0])
+	require.Nil(t, err)
+	require.True(t, proto.Equal(reportTrait, datasetTrait))
+	require.Equal(t, "2", datasetUsers[1].Id.Resource)
+
+	_, err = New(ctx, "mock-company", "mock-access-token", WithDataSource("spreadsheet"))
+	require.Error(t, err)
+}
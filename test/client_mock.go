@@ -0,0 +1,671 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package test
+
+import (
+	"context"
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"sync"
+)
+
+// Ensure, that ClientMock does implement client.Client.
+// If this is not the case, regenerate this file with moq.
+var _ client.Client = &ClientMock{}
+
+// ClientMock is a mock implementation of client.Client.
+//
+//	func TestSomethingThatUsesClient(t *testing.T) {
+//
+//		// make and configure a mocked client.Client
+//		mockedClient := &ClientMock{
+//			GetCompanyInformationFunc: func(ctx context.Context) (*client.CompanyInformation, *v2.RateLimitDescription, error) {
+//				panic("mock out the GetCompanyInformation method")
+//			},
+//			GetCompanyLogoFunc: func(ctx context.Context) ([]byte, string, *v2.RateLimitDescription, error) {
+//				panic("mock out the GetCompanyLogo method")
+//			},
+//			GetEmployeePhotoFunc: func(ctx context.Context, employeeId string, size string) ([]byte, string, *v2.RateLimitDescription, error) {
+//				panic("mock out the GetEmployeePhoto method")
+//			},
+//			ListCompanyBenefitsFunc: func(ctx context.Context) ([]*client.CompanyBenefit, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListCompanyBenefits method")
+//			},
+//			ListEmployeeBenefitsFunc: func(ctx context.Context) ([]*client.EmployeeBenefit, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListEmployeeBenefits method")
+//			},
+//			ListEmployeeTableRowsFunc: func(ctx context.Context, employeeId string, table string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListEmployeeTableRows method")
+//			},
+//			ListFilteredUsersFunc: func(ctx context.Context, filters *client.ReportFilters, extraFields ...string) ([]*client.User, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListFilteredUsers method")
+//			},
+//			ListListFieldsFunc: func(ctx context.Context) ([]*client.ListField, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListListFields method")
+//			},
+//			ListLoginUsersFunc: func(ctx context.Context) ([]*client.LoginUser, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListLoginUsers method")
+//			},
+//			ListTableRowsFunc: func(ctx context.Context, table string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListTableRows method")
+//			},
+//			ListUsersFunc: func(ctx context.Context, extraFields ...string) ([]*client.User, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListUsers method")
+//			},
+//			ListUsersPageFunc: func(ctx context.Context, filters *client.ReportFilters, page string, extraFields ...string) ([]*client.User, string, *v2.RateLimitDescription, error) {
+//				panic("mock out the ListUsersPage method")
+//			},
+//			VerifyFunc: func(ctx context.Context) error {
+//				panic("mock out the Verify method")
+//			},
+//		}
+//
+//		// use mockedClient in code that requires client.Client
+//		// and then make assertions.
+//
+//	}
+type ClientMock struct {
+	// GetCompanyInformationFunc mocks the GetCompanyInformation method.
+	GetCompanyInformationFunc func(ctx context.Context) (*client.CompanyInformation, *v2.RateLimitDescription, error)
+
+	// GetCompanyLogoFunc mocks the GetCompanyLogo method.
+	GetCompanyLogoFunc func(ctx context.Context) ([]byte, string, *v2.RateLimitDescription, error)
+
+	// GetEmployeePhotoFunc mocks the GetEmployeePhoto method.
+	GetEmployeePhotoFunc func(ctx context.Context, employeeId string, size string) ([]byte, string, *v2.RateLimitDescription, error)
+
+	// ListCompanyBenefitsFunc mocks the ListCompanyBenefits method.
+	ListCompanyBenefitsFunc func(ctx context.Context) ([]*client.CompanyBenefit, *v2.RateLimitDescription, error)
+
+	// ListEmployeeBenefitsFunc mocks the ListEmployeeBenefits method.
+	ListEmployeeBenefitsFunc func(ctx context.Context) ([]*client.EmployeeBenefit, *v2.RateLimitDescription, error)
+
+	// ListEmployeeTableRowsFunc mocks the ListEmployeeTableRows method.
+	ListEmployeeTableRowsFunc func(ctx context.Context, employeeId string, table string) ([]client.TableRow, *v2.RateLimitDescription, error)
+
+	// ListFilteredUsersFunc mocks the ListFilteredUsers method.
+	ListFilteredUsersFunc func(ctx context.Context, filters *client.ReportFilters, extraFields ...string) ([]*client.User, *v2.RateLimitDescription, error)
+
+	// ListListFieldsFunc mocks the ListListFields method.
+	ListListFieldsFunc func(ctx context.Context) ([]*client.ListField, *v2.RateLimitDescription, error)
+
+	// ListLoginUsersFunc mocks the ListLoginUsers method.
+	ListLoginUsersFunc func(ctx context.Context) ([]*client.LoginUser, *v2.RateLimitDescription, error)
+
+	// ListTableRowsFunc mocks the ListTableRows method.
+	ListTableRowsFunc func(ctx context.Context, table string) ([]client.TableRow, *v2.RateLimitDescription, error)
+
+	// ListUsersFunc mocks the ListUsers method.
+	ListUsersFunc func(ctx context.Context, extraFields ...string) ([]*client.User, *v2.RateLimitDescription, error)
+
+	// ListUsersPageFunc mocks the ListUsersPage method.
+	ListUsersPageFunc func(ctx context.Context, filters *client.ReportFilters, page string, extraFields ...string) ([]*client.User, string, *v2.RateLimitDescription, error)
+
+	// VerifyFunc mocks the Verify method.
+	VerifyFunc func(ctx context.Context) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetCompanyInformation holds details about calls to the GetCompanyInformation method.
+		GetCompanyInformation []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetCompanyLogo holds details about calls to the GetCompanyLogo method.
+		GetCompanyLogo []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetEmployeePhoto holds details about calls to the GetEmployeePhoto method.
+		GetEmployeePhoto []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EmployeeId is the employeeId argument value.
+			EmployeeId string
+			// Size is the size argument value.
+			Size string
+		}
+		// ListCompanyBenefits holds details about calls to the ListCompanyBenefits method.
+		ListCompanyBenefits []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ListEmployeeBenefits holds details about calls to the ListEmployeeBenefits method.
+		ListEmployeeBenefits []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ListEmployeeTableRows holds details about calls to the ListEmployeeTableRows method.
+		ListEmployeeTableRows []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EmployeeId is the employeeId argument value.
+			EmployeeId string
+			// Table is the table argument value.
+			Table string
+		}
+		// ListFilteredUsers holds details about calls to the ListFilteredUsers method.
+		ListFilteredUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters *client.ReportFilters
+			// ExtraFields is the extraFields argument value.
+			ExtraFields []string
+		}
+		// ListListFields holds details about calls to the ListListFields method.
+		ListListFields []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ListLoginUsers holds details about calls to the ListLoginUsers method.
+		ListLoginUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ListTableRows holds details about calls to the ListTableRows method.
+		ListTableRows []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Table is the table argument value.
+			Table string
+		}
+		// ListUsers holds details about calls to the ListUsers method.
+		ListUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ExtraFields is the extraFields argument value.
+			ExtraFields []string
+		}
+		// ListUsersPage holds details about calls to the ListUsersPage method.
+		ListUsersPage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters *client.ReportFilters
+			// Page is the page argument value.
+			Page string
+			// ExtraFields is the extraFields argument value.
+			ExtraFields []string
+		}
+		// Verify holds details about calls to the Verify method.
+		Verify []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockGetCompanyInformation sync.RWMutex
+	lockGetCompanyLogo        sync.RWMutex
+	lockGetEmployeePhoto      sync.RWMutex
+	lockListCompanyBenefits   sync.RWMutex
+	lockListEmployeeBenefits  sync.RWMutex
+	lockListEmployeeTableRows sync.RWMutex
+	lockListFilteredUsers     sync.RWMutex
+	lockListListFields        sync.RWMutex
+	lockListLoginUsers        sync.RWMutex
+	lockListTableRows         sync.RWMutex
+	lockListUsers             sync.RWMutex
+	lockListUsersPage         sync.RWMutex
+	lockVerify                sync.RWMutex
+}
+
+// GetCompanyInformation calls GetCompanyInformationFunc.
+func (mock *ClientMock) GetCompanyInformation(ctx context.Context) (*client.CompanyInformation, *v2.RateLimitDescription, error) {
+	if mock.GetCompanyInformationFunc == nil {
+		panic("ClientMock.GetCompanyInformationFunc: method is nil but Client.GetCompanyInformation was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetCompanyInformation.Lock()
+	mock.calls.GetCompanyInformation = append(mock.calls.GetCompanyInformation, callInfo)
+	mock.lockGetCompanyInformation.Unlock()
+	return mock.GetCompanyInformationFunc(ctx)
+}
+
+// GetCompanyInformationCalls gets all the calls that were made to GetCompanyInformation.
+// Check the length with:
+//
+//	len(mockedClient.GetCompanyInformationCalls())
+func (mock *ClientMock) GetCompanyInformationCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetCompanyInformation.RLock()
+	calls = mock.calls.GetCompanyInformation
+	mock.lockGetCompanyInformation.RUnlock()
+	return calls
+}
+
+// GetCompanyLogo calls GetCompanyLogoFunc.
+func (mock *ClientMock) GetCompanyLogo(ctx context.Context) ([]byte, string, *v2.RateLimitDescription, error) {
+	if mock.GetCompanyLogoFunc == nil {
+		panic("ClientMock.GetCompanyLogoFunc: method is nil but Client.GetCompanyLogo was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetCompanyLogo.Lock()
+	mock.calls.GetCompanyLogo = append(mock.calls.GetCompanyLogo, callInfo)
+	mock.lockGetCompanyLogo.Unlock()
+	return mock.GetCompanyLogoFunc(ctx)
+}
+
+// GetCompanyLogoCalls gets all the calls that were made to GetCompanyLogo.
+// Check the length with:
+//
+//	len(mockedClient.GetCompanyLogoCalls())
+func (mock *ClientMock) GetCompanyLogoCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetCompanyLogo.RLock()
+	calls = mock.calls.GetCompanyLogo
+	mock.lockGetCompanyLogo.RUnlock()
+	return calls
+}
+
+// GetEmployeePhoto calls GetEmployeePhotoFunc.
+func (mock *ClientMock) GetEmployeePhoto(ctx context.Context, employeeId string, size string) ([]byte, string, *v2.RateLimitDescription, error) {
+	if mock.GetEmployeePhotoFunc == nil {
+		panic("ClientMock.GetEmployeePhotoFunc: method is nil but Client.GetEmployeePhoto was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		EmployeeId string
+		Size       string
+	}{
+		Ctx:        ctx,
+		EmployeeId: employeeId,
+		Size:       size,
+	}
+	mock.lockGetEmployeePhoto.Lock()
+	mock.calls.GetEmployeePhoto = append(mock.calls.GetEmployeePhoto, callInfo)
+	mock.lockGetEmployeePhoto.Unlock()
+	return mock.GetEmployeePhotoFunc(ctx, employeeId, size)
+}
+
+// GetEmployeePhotoCalls gets all the calls that were made to GetEmployeePhoto.
+// Check the length with:
+//
+//	len(mockedClient.GetEmployeePhotoCalls())
+func (mock *ClientMock) GetEmployeePhotoCalls() []struct {
+	Ctx        context.Context
+	EmployeeId string
+	Size       string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		EmployeeId string
+		Size       string
+	}
+	mock.lockGetEmployeePhoto.RLock()
+	calls = mock.calls.GetEmployeePhoto
+	mock.lockGetEmployeePhoto.RUnlock()
+	return calls
+}
+
+// ListCompanyBenefits calls ListCompanyBenefitsFunc.
+func (mock *ClientMock) ListCompanyBenefits(ctx context.Context) ([]*client.CompanyBenefit, *v2.RateLimitDescription, error) {
+	if mock.ListCompanyBenefitsFunc == nil {
+		panic("ClientMock.ListCompanyBenefitsFunc: method is nil but Client.ListCompanyBenefits was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListCompanyBenefits.Lock()
+	mock.calls.ListCompanyBenefits = append(mock.calls.ListCompanyBenefits, callInfo)
+	mock.lockListCompanyBenefits.Unlock()
+	return mock.ListCompanyBenefitsFunc(ctx)
+}
+
+// ListCompanyBenefitsCalls gets all the calls that were made to ListCompanyBenefits.
+// Check the length with:
+//
+//	len(mockedClient.ListCompanyBenefitsCalls())
+func (mock *ClientMock) ListCompanyBenefitsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListCompanyBenefits.RLock()
+	calls = mock.calls.ListCompanyBenefits
+	mock.lockListCompanyBenefits.RUnlock()
+	return calls
+}
+
+// ListEmployeeBenefits calls ListEmployeeBenefitsFunc.
+func (mock *ClientMock) ListEmployeeBenefits(ctx context.Context) ([]*client.EmployeeBenefit, *v2.RateLimitDescription, error) {
+	if mock.ListEmployeeBenefitsFunc == nil {
+		panic("ClientMock.ListEmployeeBenefitsFunc: method is nil but Client.ListEmployeeBenefits was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListEmployeeBenefits.Lock()
+	mock.calls.ListEmployeeBenefits = append(mock.calls.ListEmployeeBenefits, callInfo)
+	mock.lockListEmployeeBenefits.Unlock()
+	return mock.ListEmployeeBenefitsFunc(ctx)
+}
+
+// ListEmployeeBenefitsCalls gets all the calls that were made to ListEmployeeBenefits.
+// Check the length with:
+//
+//	len(mockedClient.ListEmployeeBenefitsCalls())
+func (mock *ClientMock) ListEmployeeBenefitsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListEmployeeBenefits.RLock()
+	calls = mock.calls.ListEmployeeBenefits
+	mock.lockListEmployeeBenefits.RUnlock()
+	return calls
+}
+
+// ListEmployeeTableRows calls ListEmployeeTableRowsFunc.
+func (mock *ClientMock) ListEmployeeTableRows(ctx context.Context, employeeId string, table string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+	if mock.ListEmployeeTableRowsFunc == nil {
+		panic("ClientMock.ListEmployeeTableRowsFunc: method is nil but Client.ListEmployeeTableRows was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		EmployeeId string
+		Table      string
+	}{
+		Ctx:        ctx,
+		EmployeeId: employeeId,
+		Table:      table,
+	}
+	mock.lockListEmployeeTableRows.Lock()
+	mock.calls.ListEmployeeTableRows = append(mock.calls.ListEmployeeTableRows, callInfo)
+	mock.lockListEmployeeTableRows.Unlock()
+	return mock.ListEmployeeTableRowsFunc(ctx, employeeId, table)
+}
+
+// ListEmployeeTableRowsCalls gets all the calls that were made to ListEmployeeTableRows.
+// Check the length with:
+//
+//	len(mockedClient.ListEmployeeTableRowsCalls())
+func (mock *ClientMock) ListEmployeeTableRowsCalls() []struct {
+	Ctx        context.Context
+	EmployeeId string
+	Table      string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		EmployeeId string
+		Table      string
+	}
+	mock.lockListEmployeeTableRows.RLock()
+	calls = mock.calls.ListEmployeeTableRows
+	mock.lockListEmployeeTableRows.RUnlock()
+	return calls
+}
+
+// ListFilteredUsers calls ListFilteredUsersFunc.
+func (mock *ClientMock) ListFilteredUsers(ctx context.Context, filters *client.ReportFilters, extraFields ...string) ([]*client.User, *v2.RateLimitDescription, error) {
+	if mock.ListFilteredUsersFunc == nil {
+		panic("ClientMock.ListFilteredUsersFunc: method is nil but Client.ListFilteredUsers was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Filters     *client.ReportFilters
+		ExtraFields []string
+	}{
+		Ctx:         ctx,
+		Filters:     filters,
+		ExtraFields: extraFields,
+	}
+	mock.lockListFilteredUsers.Lock()
+	mock.calls.ListFilteredUsers = append(mock.calls.ListFilteredUsers, callInfo)
+	mock.lockListFilteredUsers.Unlock()
+	return mock.ListFilteredUsersFunc(ctx, filters, extraFields...)
+}
+
+// ListFilteredUsersCalls gets all the calls that were made to ListFilteredUsers.
+// Check the length with:
+//
+//	len(mockedClient.ListFilteredUsersCalls())
+func (mock *ClientMock) ListFilteredUsersCalls() []struct {
+	Ctx         context.Context
+	Filters     *client.ReportFilters
+	ExtraFields []string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Filters     *client.ReportFilters
+		ExtraFields []string
+	}
+	mock.lockListFilteredUsers.RLock()
+	calls = mock.calls.ListFilteredUsers
+	mock.lockListFilteredUsers.RUnlock()
+	return calls
+}
+
+// ListListFields calls ListListFieldsFunc.
+func (mock *ClientMock) ListListFields(ctx context.Context) ([]*client.ListField, *v2.RateLimitDescription, error) {
+	if mock.ListListFieldsFunc == nil {
+		panic("ClientMock.ListListFieldsFunc: method is nil but Client.ListListFields was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListListFields.Lock()
+	mock.calls.ListListFields = append(mock.calls.ListListFields, callInfo)
+	mock.lockListListFields.Unlock()
+	return mock.ListListFieldsFunc(ctx)
+}
+
+// ListListFieldsCalls gets all the calls that were made to ListListFields.
+// Check the length with:
+//
+//	len(mockedClient.ListListFieldsCalls())
+func (mock *ClientMock) ListListFieldsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListListFields.RLock()
+	calls = mock.calls.ListListFields
+	mock.lockListListFields.RUnlock()
+	return calls
+}
+
+// ListLoginUsers calls ListLoginUsersFunc.
+func (mock *ClientMock) ListLoginUsers(ctx context.Context) ([]*client.LoginUser, *v2.RateLimitDescription, error) {
+	if mock.ListLoginUsersFunc == nil {
+		panic("ClientMock.ListLoginUsersFunc: method is nil but Client.ListLoginUsers was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListLoginUsers.Lock()
+	mock.calls.ListLoginUsers = append(mock.calls.ListLoginUsers, callInfo)
+	mock.lockListLoginUsers.Unlock()
+	return mock.ListLoginUsersFunc(ctx)
+}
+
+// ListLoginUsersCalls gets all the calls that were made to ListLoginUsers.
+// Check the length with:
+//
+//	len(mockedClient.ListLoginUsersCalls())
+func (mock *ClientMock) ListLoginUsersCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListLoginUsers.RLock()
+	calls = mock.calls.ListLoginUsers
+	mock.lockListLoginUsers.RUnlock()
+	return calls
+}
+
+// ListTableRows calls ListTableRowsFunc.
+func (mock *ClientMock) ListTableRows(ctx context.Context, table string) ([]client.TableRow, *v2.RateLimitDescription, error) {
+	if mock.ListTableRowsFunc == nil {
+		panic("ClientMock.ListTableRowsFunc: method is nil but Client.ListTableRows was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Table string
+	}{
+		Ctx:   ctx,
+		Table: table,
+	}
+	mock.lockListTableRows.Lock()
+	mock.calls.ListTableRows = append(mock.calls.ListTableRows, callInfo)
+	mock.lockListTableRows.Unlock()
+	return mock.ListTableRowsFunc(ctx, table)
+}
+
+// ListTableRowsCalls gets all the calls that were made to ListTableRows.
+// Check the length with:
+//
+//	len(mockedClient.ListTableRowsCalls())
+func (mock *ClientMock) ListTableRowsCalls() []struct {
+	Ctx   context.Context
+	Table string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Table string
+	}
+	mock.lockListTableRows.RLock()
+	calls = mock.calls.ListTableRows
+	mock.lockListTableRows.RUnlock()
+	return calls
+}
+
+// ListUsers calls ListUsersFunc.
+func (mock *ClientMock) ListUsers(ctx context.Context, extraFields ...string) ([]*client.User, *v2.RateLimitDescription, error) {
+	if mock.ListUsersFunc == nil {
+		panic("ClientMock.ListUsersFunc: method is nil but Client.ListUsers was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		ExtraFields []string
+	}{
+		Ctx:         ctx,
+		ExtraFields: extraFields,
+	}
+	mock.lockListUsers.Lock()
+	mock.calls.ListUsers = append(mock.calls.ListUsers, callInfo)
+	mock.lockListUsers.Unlock()
+	return mock.ListUsersFunc(ctx, extraFields...)
+}
+
+// ListUsersCalls gets all the calls that were made to ListUsers.
+// Check the length with:
+//
+//	len(mockedClient.ListUsersCalls())
+func (mock *ClientMock) ListUsersCalls() []struct {
+	Ctx         context.Context
+	ExtraFields []string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		ExtraFields []string
+	}
+	mock.lockListUsers.RLock()
+	calls = mock.calls.ListUsers
+	mock.lockListUsers.RUnlock()
+	return calls
+}
+
+// ListUsersPage calls ListUsersPageFunc.
+func (mock *ClientMock) ListUsersPage(ctx context.Context, filters *client.ReportFilters, page string, extraFields ...string) ([]*client.User, string, *v2.RateLimitDescription, error) {
+	if mock.ListUsersPageFunc == nil {
+		panic("ClientMock.ListUsersPageFunc: method is nil but Client.ListUsersPage was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Filters     *client.ReportFilters
+		Page        string
+		ExtraFields []string
+	}{
+		Ctx:         ctx,
+		Filters:     filters,
+		Page:        page,
+		ExtraFields: extraFields,
+	}
+	mock.lockListUsersPage.Lock()
+	mock.calls.ListUsersPage = append(mock.calls.ListUsersPage, callInfo)
+	mock.lockListUsersPage.Unlock()
+	return mock.ListUsersPageFunc(ctx, filters, page, extraFields...)
+}
+
+// ListUsersPageCalls gets all the calls that were made to ListUsersPage.
+// Check the length with:
+//
+//	len(mockedClient.ListUsersPageCalls())
+func (mock *ClientMock) ListUsersPageCalls() []struct {
+	Ctx         context.Context
+	Filters     *client.ReportFilters
+	Page        string
+	ExtraFields []string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Filters     *client.ReportFilters
+		Page        string
+		ExtraFields []string
+	}
+	mock.lockListUsersPage.RLock()
+	calls = mock.calls.ListUsersPage
+	mock.lockListUsersPage.RUnlock()
+	return calls
+}
+
+// Verify calls VerifyFunc.
+func (mock *ClientMock) Verify(ctx context.Context) error {
+	if mock.VerifyFunc == nil {
+		panic("ClientMock.VerifyFunc: method is nil but Client.Verify was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockVerify.Lock()
+	mock.calls.Verify = append(mock.calls.Verify, callInfo)
+	mock.lockVerify.Unlock()
+	return mock.VerifyFunc(ctx)
+}
+
+// VerifyCalls gets all the calls that were made to Verify.
+// Check the length with:
+//
+//	len(mockedClient.VerifyCalls())
+func (mock *ClientMock) VerifyCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockVerify.RLock()
+	calls = mock.calls.Verify
+	mock.lockVerify.RUnlock()
+	return calls
+}
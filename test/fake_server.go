@@ -0,0 +1,975 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-bamboohr/pkg/connector/client"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+)
+
+// fakeDatasetFieldNames maps Datasets API field names to the report aliases
+// employees are stored under.
+var fakeDatasetFieldNames = map[string]string{
+	"employeeId":       "id",
+	"email":            "workEmail",
+	"supervisorEid":    "supervisorEId",
+	"employmentStatus": "employmentHistoryStatus",
+}
+
+// Failure makes the FakeServer misbehave for matching requests.
+type Failure struct {
+	// Path is matched as a substring of the request path. An empty Path
+	// matches every request.
+	Path string
+	// Status, when set, is returned instead of the real response.
+	Status int
+	// RetryAfter is sent as the Retry-After header along with Status.
+	RetryAfter string
+	// Message is sent as the X-BambooHR-Error-Message header along with
+	// Status.
+	Message string
+	// Body is sent as the response body along with Status.
+	Body string
+	// Delay is waited before responding, or until the client gives up.
+	Delay time.Duration
+	// Times is how many requests fail before the failure clears. Zero fails
+	// every matching request.
+	Times int
+}
+
+// FakeField is an entry of /meta/fields.
+type FakeField struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// FakeWebhook is a webhook registered with /webhooks.
+type FakeWebhook struct {
+	Id            string            `json:"id"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Format        string            `json:"format"`
+	MonitorFields []string          `json:"monitorFields"`
+	PostFields    map[string]string `json:"postFields"`
+	Created       string            `json:"created"`
+	PrivateKey    string            `json:"privateKey,omitempty"`
+}
+
+// FakeTimeOffRequest is a time off request from /time_off/requests.
+type FakeTimeOffRequest struct {
+	Id         string `json:"id"`
+	EmployeeId string `json:"employeeId"`
+	Status     struct {
+		LastChanged string `json:"lastChanged"`
+		Status      string `json:"status"`
+	} `json:"status"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Type  struct {
+		Id string `json:"id"`
+	} `json:"type"`
+	Amount struct {
+		Unit   string `json:"unit"`
+		Amount string `json:"amount"`
+	} `json:"amount"`
+}
+
+type fakeEmployee struct {
+	fields      map[string]string
+	created     time.Time
+	lastChanged time.Time
+	photo       []byte
+}
+
+// FakeServer is a stateful in-process fake of the BambooHR API. Data can be
+// seeded through its methods or written through the API, and every write is
+// visible to later reads. Only the behaviour the connector relies on is
+// modelled.
+type FakeServer struct {
+	*httptest.Server
+	// APIKey, when set, must be sent as the basic auth username.
+	APIKey string
+	// Now is the clock used for change tracking. It defaults to time.Now.
+	Now func() time.Time
+
+	mu                 sync.Mutex
+	ids                map[string]int
+	employees          map[string]*fakeEmployee
+	tables             map[string]map[string][]client.TableRow
+	fields             []*FakeField
+	lists              []*client.ListField
+	loginUsers         map[string]*client.LoginUser
+	webhooks           map[string]*FakeWebhook
+	timeOff            map[string]*FakeTimeOffRequest
+	companyBenefits    []*client.CompanyBenefit
+	employeeBenefits   []*client.EmployeeBenefit
+	companyInformation *client.CompanyInformation
+	companyLogo        []byte
+	failures           []*Failure
+	requests           []string
+}
+
+// NewFakeServer starts an empty FakeServer. Close it when done.
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		ids:                make(map[string]int),
+		employees:          make(map[string]*fakeEmployee),
+		tables:             make(map[string]map[string][]client.TableRow),
+		fields:             make([]*FakeField, 0),
+		lists:              make([]*client.ListField, 0),
+		loginUsers:         make(map[string]*client.LoginUser),
+		webhooks:           make(map[string]*FakeWebhook),
+		timeOff:            make(map[string]*FakeTimeOffRequest),
+		companyBenefits:    make([]*client.CompanyBenefit, 0),
+		employeeBenefits:   make([]*client.EmployeeBenefit, 0),
+		companyInformation: &client.CompanyInformation{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *FakeServer) now() time.Time {
+	if s.Now != nil {
+		return s.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// nextId must be called with the lock held.
+func (s *FakeServer) nextId(collection string) string {
+	s.ids[collection]++
+	return strconv.Itoa(s.ids[collection])
+}
+
+// AddEmployee stores a new employee and returns its id.
+func (s *FakeServer) AddEmployee(fields map[string]string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addEmployee(fields)
+}
+
+func (s *FakeServer) addEmployee(fields map[string]string) string {
+	id := s.nextId("employees")
+	employee := &fakeEmployee{
+		fields:      map[string]string{"status": "Active"},
+		created:     s.now(),
+		lastChanged: s.now(),
+	}
+	for key, value := range fields {
+		employee.fields[key] = value
+	}
+	employee.fields["id"] = id
+	s.employees[id] = employee
+	return id
+}
+
+// UpdateEmployee changes fields of an existing employee. It returns false if
+// there is no such employee.
+func (s *FakeServer) UpdateEmployee(id string, fields map[string]string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateEmployee(id, fields)
+}
+
+func (s *FakeServer) updateEmployee(id string, fields map[string]string) bool {
+	employee, ok := s.employees[id]
+	if !ok {
+		return false
+	}
+	for key, value := range fields {
+		if key != "id" {
+			employee.fields[key] = value
+		}
+	}
+	employee.lastChanged = s.now()
+	return true
+}
+
+// Employee returns a copy of an employee's fields.
+func (s *FakeServer) Employee(id string) (map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	employee, ok := s.employees[id]
+	if !ok {
+		return nil, false
+	}
+	rv := make(map[string]string, len(employee.fields))
+	for key, value := range employee.fields {
+		rv[key] = value
+	}
+	return rv, true
+}
+
+// SetEmployeePhoto uploads a photo for an employee.
+func (s *FakeServer) SetEmployeePhoto(id string, photo []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if employee, ok := s.employees[id]; ok {
+		employee.photo = photo
+		employee.fields["photoUploaded"] = strconv.FormatBool(photo != nil)
+		employee.lastChanged = s.now()
+	}
+}
+
+// AddTableRow appends a row to an employee table.
+func (s *FakeServer) AddTableRow(table string, employeeId string, row client.TableRow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addTableRow(table, employeeId, row)
+}
+
+func (s *FakeServer) addTableRow(table string, employeeId string, row client.TableRow) {
+	if s.tables[table] == nil {
+		s.tables[table] = make(map[string][]client.TableRow)
+	}
+	stored := client.TableRow{"id": s.nextId("table:" + table)}
+	for key, value := range row {
+		stored[key] = value
+	}
+	stored["employeeId"] = employeeId
+	s.tables[table][employeeId] = append(s.tables[table][employeeId], stored)
+	if employee, ok := s.employees[employeeId]; ok {
+		employee.lastChanged = s.now()
+	}
+}
+
+// AddField adds an entry to /meta/fields.
+func (s *FakeServer) AddField(field *FakeField) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fields = append(s.fields, field)
+}
+
+// AddListField adds a list field and its options to /meta/lists.
+func (s *FakeServer) AddListField(field *client.ListField) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists = append(s.lists, field)
+}
+
+// AddLoginUser adds a login account to /meta/users.
+func (s *FakeServer) AddLoginUser(user *client.LoginUser) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user.Id == "" {
+		user.Id = client.FlexibleString(s.nextId("users"))
+	}
+	s.loginUsers[user.Id.String()] = user
+}
+
+// AddCompanyBenefit adds a benefit plan.
+func (s *FakeServer) AddCompanyBenefit(benefit *client.CompanyBenefit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.companyBenefits = append(s.companyBenefits, benefit)
+}
+
+// AddEmployeeBenefit enrolls an employee in a benefit plan.
+func (s *FakeServer) AddEmployeeBenefit(benefit *client.EmployeeBenefit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.employeeBenefits = append(s.employeeBenefits, benefit)
+}
+
+// SetCompany sets the company information and logo.
+func (s *FakeServer) SetCompany(info *client.CompanyInformation, logo []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.companyInformation = info
+	s.companyLogo = logo
+}
+
+// Webhooks returns the registered webhooks ordered by id.
+func (s *FakeServer) Webhooks() []*FakeWebhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedById(s.webhooks)
+}
+
+// TimeOffRequests returns every time off request ordered by id.
+func (s *FakeServer) TimeOffRequests() []*FakeTimeOffRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedById(s.timeOff)
+}
+
+// InjectFailure makes matching requests fail until the failure clears.
+func (s *FakeServer) InjectFailure(failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure)
+}
+
+// Requests returns the method and path of every request received so far.
+func (s *FakeServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func (s *FakeServer) serveHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, request.Method+" "+request.URL.Path)
+	failure := s.matchFailure(request)
+	s.mu.Unlock()
+
+	if failure != nil {
+		if failure.Delay > 0 {
+			select {
+			case <-time.After(failure.Delay):
+			case <-request.Context().Done():
+				return
+			}
+		}
+		if failure.Status != 0 {
+			if failure.RetryAfter != "" {
+				writer.Header().Set("Retry-After", failure.RetryAfter)
+			}
+			if failure.Message != "" {
+				writer.Header().Set(client.ErrorMessageHeader, failure.Message)
+			}
+			writer.WriteHeader(failure.Status)
+			_, _ = writer.Write([]byte(failure.Body))
+			return
+		}
+	}
+
+	if s.APIKey != "" {
+		username, _, ok := request.BasicAuth()
+		if !ok || username != s.APIKey {
+			writer.Header().Set(client.ErrorMessageHeader, "Invalid API key")
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// BambooHR falls back to XML unless JSON is asked for, which the client
+	// cannot decode.
+	if !strings.Contains(request.Header.Get("Accept"), client.JSONContentType) {
+		writer.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	_, path, ok := strings.Cut(request.URL.Path, "/"+client.APIVersion+"/")
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	segments := slices.DeleteFunc(strings.Split(path, "/"), func(segment string) bool {
+		return segment == ""
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.route(writer, request, segments)
+}
+
+// matchFailure must be called with the lock held.
+func (s *FakeServer) matchFailure(request *http.Request) *Failure {
+	for i, failure := range s.failures {
+		if !strings.Contains(request.URL.Path, failure.Path) {
+			continue
+		}
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				s.failures = slices.Delete(s.failures, i, i+1)
+			}
+		}
+		return failure
+	}
+	return nil
+}
+
+// route must be called with the lock held.
+func (s *FakeServer) route(writer http.ResponseWriter, request *http.Request, segments []string) {
+	method := request.Method
+	switch {
+	case matches(segments, "reports", "custom") && method == http.MethodPost:
+		s.serveReport(writer, request)
+	case matches(segments, "datasets", "employee") && method == http.MethodPost:
+		s.serveDataset(writer, request)
+	case matches(segments, "employees", "directory") && method == http.MethodGet:
+		s.serveDirectory(writer)
+	case matches(segments, "employees", "changed") && method == http.MethodGet:
+		s.serveChanged(writer, request)
+	case matches(segments, "employees", "changed", "tables", "*") && method == http.MethodGet:
+		s.serveChangedTable(writer, request, segments[3])
+	case matches(segments, "employees") && method == http.MethodPost:
+		s.createEmployee(writer, request)
+	case matches(segments, "employees", "*") && method == http.MethodGet:
+		s.serveEmployee(writer, request, segments[1])
+	case matches(segments, "employees", "*") && method == http.MethodPost:
+		s.writeEmployee(writer, request, segments[1])
+	case matches(segments, "employees", "*", "photo", "*") && method == http.MethodGet:
+		s.servePhoto(writer, segments[1])
+	case matches(segments, "employees", "*", "tables", "*") && method == http.MethodGet:
+		s.serveTable(writer, segments[1], segments[3])
+	case matches(segments, "employees", "*", "tables", "*") && method == http.MethodPost:
+		s.writeTableRow(writer, request, segments[1], segments[3])
+	case matches(segments, "employees", "*", "time_off", "request") && method == http.MethodPut:
+		s.createTimeOff(writer, request, segments[1])
+	case matches(segments, "time_off", "requests") && method == http.MethodGet:
+		s.serveTimeOff(writer, request)
+	case matches(segments, "time_off", "requests", "*", "status") && method == http.MethodPut:
+		s.writeTimeOffStatus(writer, request, segments[2])
+	case matches(segments, "meta", "fields") && method == http.MethodGet:
+		writeJSON(writer, http.StatusOK, s.fields)
+	case matches(segments, "meta", "lists") && method == http.MethodGet:
+		writeJSON(writer, http.StatusOK, s.lists)
+	case matches(segments, "meta", "lists", "*") && method == http.MethodPut:
+		s.writeListOptions(writer, request, segments[2])
+	case matches(segments, "meta", "users") && method == http.MethodGet:
+		writeJSON(writer, http.StatusOK, s.loginUsers)
+	case matches(segments, "webhooks") && method == http.MethodGet:
+		writeJSON(writer, http.StatusOK, map[string]interface{}{"webhooks": sortedById(s.webhooks)})
+	case matches(segments, "webhooks") && method == http.MethodPost:
+		s.createWebhook(writer, request)
+	case matches(segments, "webhooks", "*") && method == http.MethodGet:
+		s.serveWebhook(writer, segments[1])
+	case matches(segments, "webhooks", "*") && method == http.MethodDelete:
+		s.deleteWebhook(writer, segments[1])
+	case matches(segments, "benefit", "company_benefit") && method == http.MethodGet:
+		writeJSON(writer, http.StatusOK, s.companyBenefits)
+	case matches(segments, "benefit", "employee_benefit") && method == http.MethodGet:
+		writeJSON(writer, http.StatusOK, s.employeeBenefits)
+	case matches(segments, "company_information") && method == http.MethodGet:
+		writeJSON(writer, http.StatusOK, s.companyInformation)
+	case matches(segments, "company_information", "logo") && method == http.MethodGet:
+		writeImage(writer, s.companyLogo)
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// matches reports whether segments equal pattern, where "*" matches any
+// single segment.
+func matches(segments []string, pattern ...string) bool {
+	if len(segments) != len(pattern) {
+		return false
+	}
+	for i, segment := range segments {
+		if pattern[i] != "*" && pattern[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body interface{}) {
+	writer.Header().Set(uhttp.ContentType, "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(body)
+}
+
+func writeImage(writer http.ResponseWriter, image []byte) {
+	if image == nil {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writer.Header().Set(uhttp.ContentType, http.DetectContentType(image))
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(image)
+}
+
+func decodeBody(writer http.ResponseWriter, request *http.Request, target interface{}) bool {
+	err := json.NewDecoder(request.Body).Decode(target)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func sortedById[T any](items map[string]*T) []*T {
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sortIds(ids)
+	rv := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		rv = append(rv, items[id])
+	}
+	return rv
+}
+
+// sortIds orders numeric ids numerically and any others after them.
+func sortIds(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, aErr := strconv.Atoi(ids[i])
+		b, bErr := strconv.Atoi(ids[j])
+		if aErr == nil && bErr == nil {
+			return a < b
+		}
+		if (aErr == nil) != (bErr == nil) {
+			return aErr == nil
+		}
+		return ids[i] < ids[j]
+	})
+}
+
+// employeeIds must be called with the lock held.
+func (s *FakeServer) employeeIds() []string {
+	ids := make([]string, 0, len(s.employees))
+	for id := range s.employees {
+		ids = append(ids, id)
+	}
+	sortIds(ids)
+	return ids
+}
+
+// row returns the requested fields of an employee, with null for fields it
+// does not have.
+func (e *fakeEmployee) row(fields []string) map[string]interface{} {
+	row := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := e.fields[field]; ok {
+			row[field] = value
+		} else {
+			row[field] = nil
+		}
+	}
+	return row
+}
+
+func (s *FakeServer) serveReport(writer http.ResponseWriter, request *http.Request) {
+	report := &client.ReqFields{}
+	if !decodeBody(writer, request, report) {
+		return
+	}
+
+	var since time.Time
+	if report.Filters != nil && report.Filters.LastChanged != nil {
+		parsed, err := time.Parse(time.RFC3339, report.Filters.LastChanged.Value)
+		if err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	fields := make([]map[string]string, 0, len(report.Fields))
+	for _, field := range report.Fields {
+		fields = append(fields, map[string]string{"id": field, "type": "text", "name": field})
+	}
+	employees := make([]map[string]interface{}, 0)
+	for _, id := range s.employeeIds() {
+		employee := s.employees[id]
+		if employee.lastChanged.Before(since) {
+			continue
+		}
+		employees = append(employees, employee.row(append([]string{"id"}, report.Fields...)))
+	}
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"title":     report.Title,
+		"fields":    fields,
+		"employees": employees,
+	})
+}
+
+func (s *FakeServer) serveDataset(writer http.ResponseWriter, request *http.Request) {
+	query := &client.DatasetQuery{}
+	if !decodeBody(writer, request, query) {
+		return
+	}
+	page, err := strconv.Atoi(request.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(request.URL.Query().Get("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = client.DatasetPageSize
+	}
+
+	var (
+		since  time.Time
+		status string
+	)
+	if query.Filters != nil {
+		for _, filter := range query.Filters.Filters {
+			switch {
+			case filter.Field == "lastChanged" && filter.Operator == "gte":
+				parsed, err := time.Parse(time.RFC3339, filter.Value)
+				if err != nil {
+					writer.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				since = parsed
+			case filter.Field == "status" && filter.Operator == "equal":
+				status = filter.Value
+			default:
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	matched := make([]*fakeEmployee, 0)
+	for _, id := range s.employeeIds() {
+		employee := s.employees[id]
+		if employee.lastChanged.Before(since) {
+			continue
+		}
+		if status != "" && !strings.EqualFold(employee.fields["status"], status) {
+			continue
+		}
+		matched = append(matched, employee)
+	}
+
+	totalPages := int(math.Ceil(float64(len(matched)) / float64(pageSize)))
+	start := min((page-1)*pageSize, len(matched))
+	end := min(start+pageSize, len(matched))
+	data := make([]map[string]interface{}, 0, end-start)
+	for _, employee := range matched[start:end] {
+		row := make(map[string]interface{}, len(query.Fields))
+		for _, field := range query.Fields {
+			alias := field
+			if name, ok := fakeDatasetFieldNames[field]; ok {
+				alias = name
+			}
+			if value, ok := employee.fields[alias]; ok {
+				row[field] = value
+			} else {
+				row[field] = nil
+			}
+		}
+		data = append(data, row)
+	}
+
+	var nextPage interface{}
+	if page < totalPages {
+		nextPage = fmt.Sprintf("%s?page=%d&page_size=%d", request.URL.Path, page+1, pageSize)
+	}
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"data": data,
+		"pagination": map[string]interface{}{
+			"total_records": len(matched),
+			"current_page":  page,
+			"total_pages":   totalPages,
+			"next_page":     nextPage,
+		},
+	})
+}
+
+func (s *FakeServer) serveDirectory(writer http.ResponseWriter) {
+	fields := []string{"id", "displayName", "firstName", "lastName", "jobTitle", "workEmail", "department"}
+	employees := make([]map[string]interface{}, 0, len(s.employees))
+	for _, id := range s.employeeIds() {
+		employees = append(employees, s.employees[id].row(fields))
+	}
+	writeJSON(writer, http.StatusOK, map[string]interface{}{"employees": employees})
+}
+
+func (s *FakeServer) serveChanged(writer http.ResponseWriter, request *http.Request) {
+	since, err := time.Parse(time.RFC3339, request.URL.Query().Get("since"))
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	changed := make(map[string]interface{})
+	for id, employee := range s.employees {
+		if employee.lastChanged.Before(since) {
+			continue
+		}
+		action := "Updated"
+		if !employee.created.Before(since) {
+			action = "Inserted"
+		}
+		changed[id] = map[string]string{
+			"id":          id,
+			"action":      action,
+			"lastChanged": employee.lastChanged.Format(time.RFC3339),
+		}
+	}
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"latest":    s.now().Format(time.RFC3339),
+		"employees": changed,
+	})
+}
+
+func (s *FakeServer) serveChangedTable(writer http.ResponseWriter, request *http.Request, table string) {
+	since, err := time.Parse(time.RFC3339, request.URL.Query().Get("since"))
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	changed := make(map[string]*client.ChangedTableEmployee)
+	for employeeId, rows := range s.tables[table] {
+		employee, ok := s.employees[employeeId]
+		if ok && employee.lastChanged.Before(since) {
+			continue
+		}
+		lastChanged := ""
+		if ok {
+			lastChanged = employee.lastChanged.Format(time.RFC3339)
+		}
+		changed[employeeId] = &client.ChangedTableEmployee{LastChanged: lastChanged, Rows: rows}
+	}
+	writeJSON(writer, http.StatusOK, &client.ChangedTableResults{Table: table, Employees: changed})
+}
+
+func (s *FakeServer) createEmployee(writer http.ResponseWriter, request *http.Request) {
+	fields := make(map[string]string)
+	if !decodeBody(writer, request, &fields) {
+		return
+	}
+	if fields["firstName"] == "" || fields["lastName"] == "" {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	id := s.addEmployee(fields)
+	writer.Header().Set("Location", strings.TrimSuffix(request.URL.Path, "/")+"/"+id)
+	writer.WriteHeader(http.StatusCreated)
+}
+
+func (s *FakeServer) serveEmployee(writer http.ResponseWriter, request *http.Request, id string) {
+	employee, ok := s.employees[id]
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	fields := []string{"id"}
+	for _, field := range strings.Split(request.URL.Query().Get("fields"), ",") {
+		if field != "" && field != "id" {
+			fields = append(fields, field)
+		}
+	}
+	writeJSON(writer, http.StatusOK, employee.row(fields))
+}
+
+func (s *FakeServer) writeEmployee(writer http.ResponseWriter, request *http.Request, id string) {
+	fields := make(map[string]string)
+	if !decodeBody(writer, request, &fields) {
+		return
+	}
+	if !s.updateEmployee(id, fields) {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (s *FakeServer) servePhoto(writer http.ResponseWriter, id string) {
+	employee, ok := s.employees[id]
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeImage(writer, employee.photo)
+}
+
+func (s *FakeServer) serveTable(writer http.ResponseWriter, employeeId string, table string) {
+	if _, ok := s.employees[employeeId]; !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	rows := s.tables[table][employeeId]
+	if rows == nil {
+		rows = make([]client.TableRow, 0)
+	}
+	writeJSON(writer, http.StatusOK, rows)
+}
+
+func (s *FakeServer) writeTableRow(writer http.ResponseWriter, request *http.Request, employeeId string, table string) {
+	if _, ok := s.employees[employeeId]; !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	row := client.TableRow{}
+	if !decodeBody(writer, request, &row) {
+		return
+	}
+	s.addTableRow(table, employeeId, row)
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (s *FakeServer) createTimeOff(writer http.ResponseWriter, request *http.Request, employeeId string) {
+	if _, ok := s.employees[employeeId]; !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body := struct {
+		Status        string `json:"status"`
+		Start         string `json:"start"`
+		End           string `json:"end"`
+		TimeOffTypeId string `json:"timeOffTypeId"`
+		Amount        string `json:"amount"`
+	}{}
+	if !decodeBody(writer, request, &body) {
+		return
+	}
+	start, startOk := client.ParseDate(body.Start)
+	end, endOk := client.ParseDate(body.End)
+	if !startOk || !endOk || end.Before(start) {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	timeOff := &FakeTimeOffRequest{
+		Id:         s.nextId("time_off"),
+		EmployeeId: employeeId,
+		Start:      body.Start,
+		End:        body.End,
+	}
+	timeOff.Status.Status = body.Status
+	if timeOff.Status.Status == "" {
+		timeOff.Status.Status = "requested"
+	}
+	timeOff.Status.LastChanged = s.now().Format(client.DateLayout)
+	timeOff.Type.Id = body.TimeOffTypeId
+	timeOff.Amount.Unit = "days"
+	timeOff.Amount.Amount = body.Amount
+	s.timeOff[timeOff.Id] = timeOff
+	writeJSON(writer, http.StatusCreated, timeOff)
+}
+
+func (s *FakeServer) serveTimeOff(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	start, startOk := client.ParseDate(query.Get("start"))
+	end, endOk := client.ParseDate(query.Get("end"))
+	if !startOk || !endOk {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	requests := make([]*FakeTimeOffRequest, 0)
+	for _, timeOff := range sortedById(s.timeOff) {
+		if employeeId := query.Get("employeeId"); employeeId != "" && timeOff.EmployeeId != employeeId {
+			continue
+		}
+		if status := query.Get("status"); status != "" && !strings.EqualFold(timeOff.Status.Status, status) {
+			continue
+		}
+		requestStart, _ := client.ParseDate(timeOff.Start)
+		requestEnd, _ := client.ParseDate(timeOff.End)
+		if requestEnd.Before(start) || requestStart.After(end) {
+			continue
+		}
+		requests = append(requests, timeOff)
+	}
+	writeJSON(writer, http.StatusOK, requests)
+}
+
+func (s *FakeServer) writeTimeOffStatus(writer http.ResponseWriter, request *http.Request, id string) {
+	timeOff, ok := s.timeOff[id]
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body := struct {
+		Status string `json:"status"`
+	}{}
+	if !decodeBody(writer, request, &body) {
+		return
+	}
+	if !slices.Contains([]string{"approved", "denied", "declined", "canceled"}, body.Status) {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	timeOff.Status.Status = body.Status
+	timeOff.Status.LastChanged = s.now().Format(client.DateLayout)
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (s *FakeServer) writeListOptions(writer http.ResponseWriter, request *http.Request, fieldId string) {
+	var list *client.ListField
+	for _, candidate := range s.lists {
+		if candidate.FieldId.String() == fieldId {
+			list = candidate
+		}
+	}
+	if list == nil {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body := struct {
+		Options []struct {
+			Id       string `json:"id"`
+			Value    string `json:"value"`
+			Archived string `json:"archived"`
+		} `json:"options"`
+	}{}
+	if !decodeBody(writer, request, &body) {
+		return
+	}
+
+	for _, option := range body.Options {
+		var existing *client.ListOption
+		for _, candidate := range list.Options {
+			if option.Id != "" && candidate.Id.String() == option.Id {
+				existing = candidate
+			}
+		}
+		if existing == nil {
+			existing = &client.ListOption{
+				Id:       client.FlexibleString(s.nextId("list:" + fieldId)),
+				Archived: "no",
+			}
+			list.Options = append(list.Options, existing)
+		}
+		if option.Value != "" {
+			existing.Name = option.Value
+		}
+		if option.Archived != "" {
+			existing.Archived = option.Archived
+			if strings.EqualFold(option.Archived, "yes") {
+				existing.ArchivedDate = s.now().Format(time.RFC3339)
+			}
+		}
+	}
+	writeJSON(writer, http.StatusOK, list.Options)
+}
+
+func (s *FakeServer) createWebhook(writer http.ResponseWriter, request *http.Request) {
+	webhook := &FakeWebhook{}
+	if !decodeBody(writer, request, webhook) {
+		return
+	}
+	if webhook.Name == "" || !strings.HasPrefix(webhook.URL, "https://") || len(webhook.MonitorFields) == 0 {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if webhook.Format == "" {
+		webhook.Format = "json"
+	}
+	webhook.Id = s.nextId("webhooks")
+	webhook.Created = s.now().Format(time.DateTime)
+	s.webhooks[webhook.Id] = webhook
+
+	created := *webhook
+	created.PrivateKey = "fake-private-key-" + webhook.Id
+	writeJSON(writer, http.StatusCreated, &created)
+}
+
+func (s *FakeServer) serveWebhook(writer http.ResponseWriter, id string) {
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(writer, http.StatusOK, webhook)
+}
+
+func (s *FakeServer) deleteWebhook(writer http.ResponseWriter, id string) {
+	if _, ok := s.webhooks[id]; !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(s.webhooks, id)
+	writer.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,3 @@
+package test
+
+//go:generate go run github.com/matryer/moq@v0.5.3 -out client_mock.go -pkg test ../pkg/connector/client Client
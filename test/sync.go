@@ -0,0 +1,181 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/dotc1z"
+	sdkSync "github.com/conductorone/baton-sdk/pkg/sync"
+	"github.com/conductorone/baton-sdk/pkg/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type connectorClient struct {
+	v2.ResourceTypesServiceClient
+	v2.ResourcesServiceClient
+	v2.EntitlementsServiceClient
+	v2.GrantsServiceClient
+	v2.ConnectorServiceClient
+	v2.AssetServiceClient
+	v2.GrantManagerServiceClient
+	v2.ResourceManagerServiceClient
+	v2.AccountManagerServiceClient
+	v2.CredentialManagerServiceClient
+	v2.EventServiceClient
+	v2.TicketsServiceClient
+}
+
+// serveConnector serves a connector over gRPC on a local port, the way the
+// connector runner does, and returns a client for it.
+func serveConnector(t *testing.T, server types.ConnectorServer) types.ConnectorClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	grpcServer := grpc.NewServer()
+	v2.RegisterConnectorServiceServer(grpcServer, server)
+	v2.RegisterGrantsServiceServer(grpcServer, server)
+	v2.RegisterEntitlementsServiceServer(grpcServer, server)
+	v2.RegisterResourcesServiceServer(grpcServer, server)
+	v2.RegisterResourceTypesServiceServer(grpcServer, server)
+	v2.RegisterAssetServiceServer(grpcServer, server)
+	v2.RegisterEventServiceServer(grpcServer, server)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &connectorClient{
+		ResourceTypesServiceClient:     v2.NewResourceTypesServiceClient(conn),
+		ResourcesServiceClient:         v2.NewResourcesServiceClient(conn),
+		EntitlementsServiceClient:      v2.NewEntitlementsServiceClient(conn),
+		GrantsServiceClient:            v2.NewGrantsServiceClient(conn),
+		ConnectorServiceClient:         v2.NewConnectorServiceClient(conn),
+		AssetServiceClient:             v2.NewAssetServiceClient(conn),
+		GrantManagerServiceClient:      v2.NewGrantManagerServiceClient(conn),
+		ResourceManagerServiceClient:   v2.NewResourceManagerServiceClient(conn),
+		AccountManagerServiceClient:    v2.NewAccountManagerServiceClient(conn),
+		CredentialManagerServiceClient: v2.NewCredentialManagerServiceClient(conn),
+		EventServiceClient:             v2.NewEventServiceClient(conn),
+		TicketsServiceClient:           v2.NewTicketsServiceClient(conn),
+	}
+}
+
+// SyncSnapshot runs a full sync of a connector into a new c1z file and
+// returns its resource types, resources, entitlements and grants as
+// normalized JSON: objects are sorted by id and formatted stably, so the
+// result can be compared with a golden file.
+func SyncSnapshot(ctx context.Context, t *testing.T, server types.ConnectorServer) []byte {
+	tmpDir := t.TempDir()
+	c1zPath := filepath.Join(tmpDir, "sync.c1z")
+
+	syncer, err := sdkSync.NewSyncer(
+		ctx,
+		serveConnector(t, server),
+		sdkSync.WithC1ZPath(c1zPath),
+		sdkSync.WithTmpDir(tmpDir),
+	)
+	require.Nil(t, err)
+	require.Nil(t, syncer.Sync(ctx))
+	require.Nil(t, syncer.Close(ctx))
+
+	c1z, err := dotc1z.NewC1ZFile(ctx, c1zPath, dotc1z.WithTmpDir(tmpDir))
+	require.Nil(t, err)
+	defer c1z.Close()
+
+	snapshot := map[string][]interface{}{
+		"resource_types": make([]interface{}, 0),
+		"resources":      make([]interface{}, 0),
+		"entitlements":   make([]interface{}, 0),
+		"grants":         make([]interface{}, 0),
+	}
+	add := func(key string, sortKey string, message proto.Message) {
+		data, err := protojson.Marshal(message)
+		require.Nil(t, err)
+		var normalized map[string]interface{}
+		require.Nil(t, json.Unmarshal(data, &normalized))
+		normalized["~sortKey"] = sortKey
+		snapshot[key] = append(snapshot[key], normalized)
+	}
+
+	pageToken := ""
+	for {
+		response, err := c1z.ListResourceTypes(ctx, &v2.ResourceTypesServiceListResourceTypesRequest{PageToken: pageToken})
+		require.Nil(t, err)
+		for _, resourceType := range response.List {
+			add("resource_types", resourceType.Id, resourceType)
+		}
+		if pageToken = response.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	for {
+		response, err := c1z.ListResources(ctx, &v2.ResourcesServiceListResourcesRequest{PageToken: pageToken})
+		require.Nil(t, err)
+		for _, resource := range response.List {
+			add("resources", resource.Id.ResourceType+"/"+resource.Id.Resource, resource)
+		}
+		if pageToken = response.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	for {
+		response, err := c1z.ListEntitlements(ctx, &v2.EntitlementsServiceListEntitlementsRequest{PageToken: pageToken})
+		require.Nil(t, err)
+		for _, entitlement := range response.List {
+			add("entitlements", entitlement.Id, entitlement)
+		}
+		if pageToken = response.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	for {
+		response, err := c1z.ListGrants(ctx, &v2.GrantsServiceListGrantsRequest{PageToken: pageToken})
+		require.Nil(t, err)
+		for _, grant := range response.List {
+			add("grants", grant.Id, grant)
+		}
+		if pageToken = response.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	for _, objects := range snapshot {
+		sort.Slice(objects, func(i, j int) bool {
+			return objects[i].(map[string]interface{})["~sortKey"].(string) <
+				objects[j].(map[string]interface{})["~sortKey"].(string)
+		})
+		for _, object := range objects {
+			delete(object.(map[string]interface{}), "~sortKey")
+		}
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	require.Nil(t, err)
+	return append(data, '\n')
+}
+
+// AssertGolden compares actual with the golden file at path, or rewrites the
+// golden file when update is set.
+func AssertGolden(t *testing.T, path string, actual []byte, update bool) {
+	if update {
+		require.Nil(t, os.WriteFile(path, actual, 0o600))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	require.Nil(t, err, "golden file missing, run the test with -update to create it")
+	require.Equal(t, string(expected), string(actual), "sync output changed, run the test with -update if this is expected")
+}
@@ -45,17 +45,53 @@ func FixturesServer() *httptest.Server {
 	return httptest.NewServer(
 		http.HandlerFunc(
 			func(writer http.ResponseWriter, request *http.Request) {
-				writer.Header().Set(uhttp.ContentType, "application/json")
-				writer.WriteHeader(http.StatusOK)
 				var filename string
 				routeUrl := request.URL.String()
 				switch {
+				case strings.Contains(routeUrl, client.EmployeesUrlPath+"/missing/photo"):
+					writer.WriteHeader(http.StatusNotFound)
+					return
+				case strings.Contains(routeUrl, "/photo/"):
+					filename = "../../test/fixtures/employee_photo.png"
+				case strings.Contains(routeUrl, client.DatasetsEmployeeUrlPath):
+					filename = fmt.Sprintf(
+						"../../test/fixtures/datasets_employee_page%s.json",
+						request.URL.Query().Get("page"),
+					)
 				case strings.Contains(routeUrl, client.UsersListUrlPath):
 					filename = "../../test/fixtures/users_report.json"
+				case strings.Contains(routeUrl, client.CompanyBenefitsUrlPath):
+					filename = "../../test/fixtures/company_benefits.json"
+				case strings.Contains(routeUrl, client.EmployeeBenefitsUrlPath):
+					filename = "../../test/fixtures/employee_benefits.json"
+				case strings.Contains(routeUrl, client.ChangedTablesUrlPath+"/assets"):
+					filename = "../../test/fixtures/assets_table.json"
+				case strings.Contains(routeUrl, client.ChangedTablesUrlPath+"/customSystemAccess"):
+					filename = "../../test/fixtures/system_access_table.json"
+				case strings.Contains(routeUrl, client.ChangedTablesUrlPath+"/jobInfo"):
+					filename = "../../test/fixtures/job_info_table.json"
+				case strings.Contains(routeUrl, client.ChangedTablesUrlPath+"/employmentStatus"):
+					filename = "../../test/fixtures/employment_status_table.json"
+				case strings.Contains(routeUrl, client.CompanyLogoUrlPath):
+					filename = "../../test/fixtures/company_logo.png"
+				case strings.Contains(routeUrl, client.CompanyInfoUrlPath):
+					filename = "../../test/fixtures/company_information.json"
+				case strings.Contains(routeUrl, client.MetaUsersUrlPath):
+					filename = "../../test/fixtures/meta_users.json"
+				case strings.Contains(routeUrl, client.MetaFieldsUrlPath):
+					filename = "../../test/fixtures/meta_fields.json"
+				case strings.Contains(routeUrl, client.MetaListsUrlPath):
+					filename = "../../test/fixtures/meta_lists.json"
 				default:
 					// This should never happen in tests.
 					panic(fmt.Errorf("bad url: %s", routeUrl))
 				}
+				contentType := "application/json"
+				if strings.HasSuffix(filename, ".png") {
+					contentType = "image/png"
+				}
+				writer.Header().Set(uhttp.ContentType, contentType)
+				writer.WriteHeader(http.StatusOK)
 				data, _ := os.ReadFile(filename)
 				_, err := writer.Write(data)
 				if err != nil {